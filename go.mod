@@ -3,14 +3,18 @@ module github.com/memclutter/gorequests
 go 1.18
 
 require (
+	github.com/andybalholm/brotli v1.0.5
 	github.com/jarcoal/httpmock v1.2.0
+	github.com/klauspost/compress v1.15.15
 	github.com/memclutter/gocore v0.0.23
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/stretchr/testify v1.8.0
+	golang.org/x/net v0.17.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/stretchr/objx v0.4.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	golang.org/x/text v0.13.0 // indirect
 )
@@ -0,0 +1,389 @@
+// Package gorequeststest provides an expectation based fake server for
+// testing code built on gorequests.
+package gorequeststest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pmezard/go-difflib/difflib"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TestingT is the part of testing.TB used by Server.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Cleanup(func())
+}
+
+// Server is a httptest server answering only expected requests. Unexpected
+// requests get 501 Not Implemented and fail the test with a diff against the
+// closest expectation. Call counts are verified when the test ends.
+type Server struct {
+	*httptest.Server
+	t            TestingT
+	mu           sync.Mutex
+	expectations []*Expectation
+	unexpected   int
+	received     int
+}
+
+func NewServer(t TestingT) *Server {
+	s := &Server{t: t, expectations: make([]*Expectation, 0)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(func() {
+		s.Close()
+		s.Verify()
+	})
+	return s
+}
+
+// Url joins the server url with the path formatted with args.
+func (s *Server) Url(path string, args ...interface{}) string {
+	return s.URL + fmt.Sprintf(path, args...)
+}
+
+// Expect registers an expectation, by default it must be called exactly
+// once and responds 200 OK with an empty body.
+func (s *Server) Expect(method, path string) *Expectation {
+	e := &Expectation{
+		method:     method,
+		path:       path,
+		query:      url.Values{},
+		header:     http.Header{},
+		times:      1,
+		status:     http.StatusOK,
+		respHeader: http.Header{},
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expectations = append(s.expectations, e)
+	return e
+}
+
+// Verify reports expectations called a wrong number of times, it returns
+// false when anything failed.
+func (s *Server) Verify() bool {
+	s.t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ok := s.unexpected == 0
+	for _, e := range s.expectations {
+		if e.times >= 0 && e.calls != e.times {
+			s.t.Errorf("gorequeststest: %s expected %d call(s), got %d", e, e.times, e.calls)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// Calls returns how many requests matched expectations for method and path.
+func (s *Server) Calls(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	calls := 0
+	for _, e := range s.expectations {
+		if e.method == method && e.path == path {
+			calls += e.calls
+		}
+	}
+	return calls
+}
+
+// Requests returns requests matched by expectations in arrival order.
+func (s *Server) Requests() []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := make([]*Request, 0)
+	for _, e := range s.expectations {
+		requests = append(requests, e.requests...)
+	}
+	sort.SliceStable(requests, func(i, j int) bool { return requests[i].seq < requests[j].seq })
+	return requests
+}
+
+// Request is a received request with its body already read.
+type Request struct {
+	*http.Request
+	Body []byte
+	seq  int
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	var matched, exhausted *Expectation
+	var closest *Expectation
+	closestScore := -1
+	for _, e := range s.expectations {
+		score := e.score(r, body)
+		if score == e.criteria() {
+			if e.times >= 0 && e.calls >= e.times {
+				if exhausted == nil {
+					exhausted = e
+				}
+				continue
+			}
+			matched = e
+			break
+		}
+		if score > closestScore {
+			closest, closestScore = e, score
+		}
+	}
+	if matched == nil {
+		s.unexpected++
+		s.mu.Unlock()
+		var message string
+		if exhausted != nil {
+			message = fmt.Sprintf("%s called more than %d time(s)", exhausted, exhausted.times)
+		} else {
+			message = fmt.Sprintf("unexpected request %s %s", r.Method, r.URL.RequestURI())
+			if closest != nil {
+				message += "\n" + closest.diff(r, body)
+			}
+		}
+		s.t.Errorf("gorequeststest: %s", message)
+		http.Error(w, message, http.StatusNotImplemented)
+		return
+	}
+	matched.calls++
+	s.received++
+	matched.requests = append(matched.requests, &Request{Request: r, Body: body, seq: s.received})
+	s.mu.Unlock()
+
+	matched.respond(w, r)
+}
+
+// Expectation describes an expected request and the response to it.
+type Expectation struct {
+	method     string
+	path       string
+	query      url.Values
+	header     http.Header
+	body       []byte
+	bodySet    bool
+	json       interface{}
+	jsonSet    bool
+	times      int
+	calls      int
+	requests   []*Request
+	status     int
+	respHeader http.Header
+	respBody   []byte
+	delay      time.Duration
+	fail       bool
+}
+
+func (e *Expectation) String() string {
+	return e.method + " " + e.path
+}
+
+func (e *Expectation) Query(name, value string) *Expectation {
+	e.query.Add(name, value)
+	return e
+}
+
+func (e *Expectation) Header(name, value string) *Expectation {
+	e.header.Add(name, value)
+	return e
+}
+
+func (e *Expectation) Body(body string) *Expectation {
+	e.body, e.bodySet = []byte(body), true
+	return e
+}
+
+// JSON expects a json body semantically equal to v.
+func (e *Expectation) JSON(v interface{}) *Expectation {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("gorequeststest: expected json encode error: %v", err))
+	}
+	e.json, e.jsonSet = normalizeJson(data), true
+	return e
+}
+
+// Times sets the exact number of expected calls, negative means any.
+func (e *Expectation) Times(times int) *Expectation {
+	e.times = times
+	return e
+}
+
+func (e *Expectation) AnyTimes() *Expectation {
+	return e.Times(-1)
+}
+
+func (e *Expectation) Respond(status int, body string) *Expectation {
+	e.status, e.respBody = status, []byte(body)
+	return e
+}
+
+func (e *Expectation) RespondJSON(status int, v interface{}) *Expectation {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("gorequeststest: response json encode error: %v", err))
+	}
+	e.respHeader.Set("Content-Type", "application/json")
+	e.status, e.respBody = status, data
+	return e
+}
+
+func (e *Expectation) RespondHeader(name, value string) *Expectation {
+	e.respHeader.Add(name, value)
+	return e
+}
+
+// Delay waits before responding, or until the client gives up.
+func (e *Expectation) Delay(delay time.Duration) *Expectation {
+	e.delay = delay
+	return e
+}
+
+// RespondError closes the connection without a response, so the client
+// gets a transport error.
+func (e *Expectation) RespondError() *Expectation {
+	e.fail = true
+	return e
+}
+
+func (e *Expectation) criteria() int {
+	criteria := 2 + len(e.query) + len(e.header)
+	if e.bodySet || e.jsonSet {
+		criteria++
+	}
+	return criteria
+}
+
+// score counts matching criteria, a request matches when all of them do.
+func (e *Expectation) score(r *http.Request, body []byte) int {
+	score := 0
+	if r.Method == e.method {
+		score++
+	}
+	if r.URL.Path == e.path {
+		score++
+	}
+	query := r.URL.Query()
+	for name, values := range e.query {
+		if reflect.DeepEqual(query[name], values) {
+			score++
+		}
+	}
+	for name, values := range e.header {
+		if reflect.DeepEqual(r.Header.Values(name), values) {
+			score++
+		}
+	}
+	switch {
+	case e.bodySet && bytes.Equal(body, e.body):
+		score++
+	case e.jsonSet && reflect.DeepEqual(normalizeJson(body), e.json):
+		score++
+	}
+	return score
+}
+
+func (e *Expectation) diff(r *http.Request, body []byte) string {
+	expected := []string{e.method + " " + e.path}
+	actual := []string{r.Method + " " + r.URL.Path}
+	query := r.URL.Query()
+	for _, name := range sortedKeys(e.query) {
+		expected = append(expected, "query "+name+"="+strings.Join(e.query[name], ","))
+		actual = append(actual, "query "+name+"="+strings.Join(query[name], ","))
+	}
+	for _, name := range sortedKeys(e.header) {
+		expected = append(expected, "header "+name+": "+strings.Join(e.header[name], ","))
+		actual = append(actual, "header "+name+": "+strings.Join(r.Header.Values(name), ","))
+	}
+	switch {
+	case e.bodySet:
+		expected = append(expected, strings.Split(string(e.body), "\n")...)
+		actual = append(actual, strings.Split(string(body), "\n")...)
+	case e.jsonSet:
+		expected = append(expected, indentJson(e.json)...)
+		if value := normalizeJson(body); value != nil {
+			actual = append(actual, indentJson(value)...)
+		} else {
+			actual = append(actual, strings.Split(string(body), "\n")...)
+		}
+	}
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        addNewLines(expected),
+		B:        addNewLines(actual),
+		FromFile: "Expected",
+		ToFile:   "Actual",
+		Context:  1,
+	})
+	return diff
+}
+
+func (e *Expectation) respond(w http.ResponseWriter, r *http.Request) {
+	if e.delay > 0 {
+		select {
+		case <-time.After(e.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if e.fail {
+		if hijacker, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hijacker.Hijack(); err == nil {
+				conn.Close()
+				return
+			}
+		}
+		panic(http.ErrAbortHandler)
+	}
+	for name, values := range e.respHeader {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.WriteHeader(e.status)
+	_, _ = w.Write(e.respBody)
+}
+
+func normalizeJson(data []byte) interface{} {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	return value
+}
+
+func indentJson(value interface{}) []string {
+	data, _ := json.MarshalIndent(value, "", "  ")
+	return strings.Split(string(data), "\n")
+}
+
+func addNewLines(lines []string) []string {
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = line + "\n"
+	}
+	return result
+}
+
+func sortedKeys(values map[string][]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,125 @@
+package gorequeststest
+
+import (
+	"context"
+	"fmt"
+	"github.com/memclutter/gorequests"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeT struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeT) Cleanup(f func()) { t.cleanups = append(t.cleanups, f) }
+
+func (t *fakeT) finish() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestServer(t *testing.T) {
+	// Test data
+	srv := NewServer(t)
+	srv.Expect(http.MethodPost, "/books").
+		Query("draft", "1").
+		Header("Authorization", "Bearer token").
+		JSON(map[string]interface{}{"title": "A book", "pages": 100}).
+		RespondHeader("Location", "/books/1").
+		RespondJSON(http.StatusCreated, map[string]int{"id": 1})
+	srv.Expect(http.MethodGet, "/books/1").Times(2).Respond(http.StatusOK, "A book")
+
+	// Run test target
+	created := make(map[string]int)
+	errPost := gorequests.Post(srv.Url("/books?draft=%d", 1)).
+		Header("Authorization", "Bearer token").
+		Json(map[string]interface{}{"pages": 100, "title": "A book"}).
+		ResponseJson(&created).
+		Exec()
+	var body []byte
+	errGet := gorequests.Get(srv.Url("/books/%d", 1)).ResponseRaw(&body).Exec()
+	errGetAgain := gorequests.Get(srv.Url("/books/%d", 1)).Exec()
+
+	// Assertions
+	assert.NoError(t, errPost, "should be run without error")
+	assert.NoError(t, errGet, "should be run without error")
+	assert.NoError(t, errGetAgain, "should be run without error")
+	assert.Equal(t, map[string]int{"id": 1}, created)
+	assert.Equal(t, "A book", string(body))
+	assert.Equal(t, 2, srv.Calls(http.MethodGet, "/books/1"))
+	if requests := srv.Requests(); assert.Len(t, requests, 3) {
+		assert.JSONEq(t, `{"title":"A book","pages":100}`, string(requests[0].Body))
+	}
+}
+
+func TestServerMismatch(t *testing.T) {
+	// Test data
+	ft := &fakeT{}
+	srv := NewServer(ft)
+	srv.Expect(http.MethodPost, "/books").JSON(map[string]string{"title": "A book"})
+	srv.Expect(http.MethodDelete, "/books/1")
+
+	// Run test target
+	meta := &gorequests.ResponseMeta{}
+	err := gorequests.Post(srv.Url("/books")).Json(map[string]string{"title": "B book"}).ResponseMeta(meta).Exec()
+	ft.finish()
+
+	// Assertions
+	assert.NoError(t, err, "should be run without error")
+	assert.Equal(t, http.StatusNotImplemented, meta.StatusCode)
+	if assert.Len(t, ft.errors, 3) {
+		assert.Contains(t, ft.errors[0], "unexpected request POST /books")
+		assert.Contains(t, ft.errors[0], `-  "title": "A book"`)
+		assert.Contains(t, ft.errors[0], `+  "title": "B book"`)
+		assert.Equal(t, "gorequeststest: POST /books expected 1 call(s), got 0", ft.errors[1])
+		assert.Equal(t, "gorequeststest: DELETE /books/1 expected 1 call(s), got 0", ft.errors[2])
+	}
+}
+
+func TestServerTooManyCalls(t *testing.T) {
+	// Test data
+	ft := &fakeT{}
+	srv := NewServer(ft)
+	srv.Expect(http.MethodGet, "/books")
+
+	// Run test target
+	errFirst := gorequests.Get(srv.Url("/books")).Exec()
+	errSecond := gorequests.Get(srv.Url("/books")).ResponseCodeOk(http.StatusOK).Exec()
+	ft.finish()
+
+	// Assertions
+	assert.NoError(t, errFirst, "should be run without error")
+	assert.Error(t, errSecond, "should be failed")
+	if assert.Len(t, ft.errors, 1) {
+		assert.True(t, strings.HasSuffix(ft.errors[0], "GET /books called more than 1 time(s)"), ft.errors[0])
+	}
+}
+
+func TestServerDelayAndError(t *testing.T) {
+	// Test data
+	srv := NewServer(t)
+	srv.Expect(http.MethodGet, "/slow").Delay(time.Second)
+	srv.Expect(http.MethodGet, "/broken").RespondError()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Run test target
+	errSlow := gorequests.Get(srv.Url("/slow")).Context(ctx).Exec()
+	errBroken := gorequests.Get(srv.Url("/broken")).Exec()
+
+	// Assertions
+	assert.ErrorIs(t, errSlow, context.DeadlineExceeded)
+	assert.Error(t, errBroken, "should be failed")
+}
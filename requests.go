@@ -2,6 +2,7 @@ package gorequests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/memclutter/gocore/pkg/coreslices"
@@ -13,6 +14,7 @@ import (
 )
 
 type requestsInstance struct {
+	ctx               context.Context
 	clientOverride    []ClientOverrideMiddleware
 	requestOverride   []RequestOverrideMiddleware
 	method            string
@@ -20,6 +22,7 @@ type requestsInstance struct {
 	cookies           []*http.Cookie
 	headers           http.Header
 	data              []byte
+	body              io.Reader
 	contentType       string
 	form              url.Values
 	json              interface{}
@@ -27,6 +30,15 @@ type requestsInstance struct {
 	responseFailCodes []int
 	respRaw           *[]byte
 	respJson          interface{}
+	respMeta          *ResponseMeta
+	respStream        func(item json.RawMessage) error
+	respLimit         int64
+	acceptEncoding    []string
+	compressEncoding  string
+	compressMinSize   int
+	proxy             string
+	noProxy           []string
+	tls               *TLSOptions
 }
 
 func Trace(url string, args ...any) RequestsInstance {
@@ -58,6 +70,11 @@ func Patch(url string, args ...any) RequestsInstance {
 }
 func Requests() RequestsInstance { return new(requestsInstance) }
 
+func (r *requestsInstance) Context(ctx context.Context) RequestsInstance {
+	r.ctx = ctx
+	return r
+}
+
 func (r *requestsInstance) Method(method string) RequestsInstance {
 	r.method = method
 	return r
@@ -94,6 +111,22 @@ func (r *requestsInstance) Data(data []byte, contentType ...string) RequestsInst
 	return r
 }
 
+func (r *requestsInstance) Body(body io.Reader, contentType ...string) RequestsInstance {
+	r.body = body
+	if len(contentType) > 0 {
+		r.contentType = contentType[0]
+	}
+	return r
+}
+
+func (r *requestsInstance) CompressBody(encoding string, minSize ...int) RequestsInstance {
+	r.compressEncoding = encoding
+	if len(minSize) > 0 {
+		r.compressMinSize = minSize[0]
+	}
+	return r
+}
+
 func (r *requestsInstance) Form(form url.Values) RequestsInstance {
 	r.form = form
 	return r
@@ -124,6 +157,33 @@ func (r *requestsInstance) ResponseJson(respJson interface{}) RequestsInstance {
 	return r
 }
 
+func (r *requestsInstance) ResponseMeta(respMeta *ResponseMeta) RequestsInstance {
+	r.respMeta = respMeta
+	return r
+}
+
+// ResponseJsonStream decodes a json array or newline delimited json values
+// one by one calling fn for each item instead of reading the whole body.
+func (r *requestsInstance) ResponseJsonStream(fn func(item json.RawMessage) error) RequestsInstance {
+	r.respStream = fn
+	return r
+}
+
+// ResponseLimit limits the decoded response body read by Exec, by default
+// to DefaultResponseLimit, zero or a negative limit disables the check.
+func (r *requestsInstance) ResponseLimit(limit int64) RequestsInstance {
+	if limit <= 0 {
+		limit = -1
+	}
+	r.respLimit = limit
+	return r
+}
+
+func (r *requestsInstance) AcceptEncoding(encodings ...string) RequestsInstance {
+	r.acceptEncoding = append(make([]string, 0, len(encodings)), encodings...)
+	return r
+}
+
 func (r *requestsInstance) Use(middlewares ...interface{}) RequestsInstance {
 	for _, middleware := range middlewares {
 		if co, ok := middleware.(ClientOverrideMiddleware); ok {
@@ -136,41 +196,187 @@ func (r *requestsInstance) Use(middlewares ...interface{}) RequestsInstance {
 	return r
 }
 
+// Clone copies the request configuration, a Body stream is shared between
+// the copies.
+func (r *requestsInstance) Clone() RequestsInstance {
+	clone := *r
+	clone.clientOverride = append([]ClientOverrideMiddleware(nil), r.clientOverride...)
+	clone.requestOverride = append([]RequestOverrideMiddleware(nil), r.requestOverride...)
+	if r.cookies != nil {
+		clone.cookies = append(make([]*http.Cookie, 0, len(r.cookies)), r.cookies...)
+	}
+	if r.headers != nil {
+		clone.headers = r.headers.Clone()
+	}
+	if r.form != nil {
+		clone.form = url.Values(http.Header(r.form).Clone())
+	}
+	return &clone
+}
+
 func (r *requestsInstance) Exec() (err error) {
+	res, err := r.do()
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	resBody, err := decodeResponseBody(res)
+	if err != nil {
+		return err
+	}
+	defer resBody.Close()
+	if r.respStream != nil {
+		return r.execStream(res, resBody)
+	}
+	limit := r.respLimit
+	if limit == 0 {
+		limit = DefaultResponseLimit
+	}
+	body, err := readLimited(resBody, limit)
+	if err != nil {
+		return err
+	}
+	r.setResponseMeta(res, int64(len(body)))
+	if !r.responseCodeAllowed(res.StatusCode) {
+		return responseStatusError(res, body)
+	}
+	if r.respRaw != nil {
+		*(r.respRaw) = body
+	}
+	if r.respJson != nil {
+		if err := json.Unmarshal(body, r.respJson); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *requestsInstance) setResponseMeta(res *http.Response, contentLength int64) {
+	if r.respMeta != nil {
+		*(r.respMeta) = ResponseMeta{
+			StatusCode:    res.StatusCode,
+			Status:        res.Status,
+			Header:        res.Header,
+			ContentLength: contentLength,
+			FromCache:     res.Header.Get(HeaderFromCache) == "1",
+			Stale:         res.Header.Get(HeaderCacheStale) == "1",
+		}
+	}
+}
+
+// responseStatusError reports a not allowed status with the start of the body.
+func responseStatusError(res *http.Response, body []byte) error {
+	if len(body) > 50 {
+		body = body[:50]
+	}
+	return fmt.Errorf("%s: %s", res.Status, body)
+}
+
+func (r *requestsInstance) responseCodeAllowed(code int) bool {
+	if len(r.responseFailCodes) > 0 && coreslices.IntIn(code, r.responseFailCodes) {
+		return false
+	}
+	if len(r.responseOkCodes) > 0 && !coreslices.IntIn(code, r.responseOkCodes) {
+		return false
+	}
+	return true
+}
+
+// do sends the request through the middleware and returns the response with
+// the body left unread.
+func (r *requestsInstance) do() (*http.Response, error) {
+	c, err := r.buildClient()
+	if err != nil {
+		return nil, err
+	}
+	req, err := r.buildRequest()
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Header.Get("Accept-Encoding")) == 0 && len(req.Header.Get("Range")) == 0 {
+		acceptEncoding := r.acceptEncoding
+		if acceptEncoding == nil {
+			acceptEncoding = defaultAcceptEncoding
+		}
+		if len(acceptEncoding) != 0 {
+			req.Header.Set("Accept-Encoding", strings.Join(acceptEncoding, ", "))
+		}
+	}
+	res, err := c.Do(req)
+	if err == nil && res.Request == nil {
+		// decoding checks the encodings accepted by the request
+		res.Request = req
+	}
+	return res, err
+}
+
+// buildClient creates client instance and applies middleware
+func (r *requestsInstance) buildClient() (c *http.Client, err error) {
+	c = &http.Client{}
+	if len(r.proxy) > 0 || len(r.noProxy) > 0 || r.tls != nil {
+		if c.Transport, err = routedTransport(r.proxy, r.noProxy, r.tls); err != nil {
+			return nil, err
+		}
+	}
+	for _, co := range r.clientOverride {
+		if c, err = co.ClientOverride(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// buildRequest creates the request with body, headers and cookies and
+// applies middleware
+func (r *requestsInstance) buildRequest() (*http.Request, error) {
 	var bodyReader io.Reader
 	var contentType string
 	if r.data != nil {
 		bodyReader = bytes.NewReader(r.data)
 		contentType = r.contentType
 	}
+	if r.body != nil {
+		bodyReader = r.body
+		contentType = r.contentType
+	}
 	if r.form != nil {
 		bodyReader = strings.NewReader(r.form.Encode())
 		contentType = "application/x-www-form-urlencoded"
 	} else if r.json != nil {
 		body, err := json.Marshal(r.json)
 		if err != nil {
-			return fmt.Errorf("request json body encode error: %v", err)
+			return nil, fmt.Errorf("request json body encode error: %v", err)
 		}
 		bodyReader = bytes.NewReader(body)
 		contentType = "application/json"
 	}
-
-	// Create client instance and apply middleware
-	c := &http.Client{}
-	for _, co := range r.clientOverride {
-		if c, err = co.ClientOverride(c); err != nil {
-			return err
+	var contentEncoding string
+	if bodyReader != nil && len(r.compressEncoding) != 0 {
+		compressed, ok, err := compressRequestBody(r.compressEncoding, r.compressMinSize, bodyReader)
+		if err != nil {
+			return nil, fmt.Errorf("request body compress error: %v", err)
+		}
+		bodyReader = compressed
+		if ok {
+			contentEncoding = r.compressEncoding
 		}
 	}
 
-	req, err := http.NewRequest(r.method, r.url, bodyReader)
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, r.url, bodyReader)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if len(contentType) != 0 {
 		req.Header.Set("Content-Type", contentType)
 	}
+	if len(contentEncoding) != 0 {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
 	if r.headers != nil {
 		for k, vv := range r.headers {
 			for _, v := range vv {
@@ -187,35 +393,46 @@ func (r *requestsInstance) Exec() (err error) {
 
 	for _, ro := range r.requestOverride {
 		if req, err = ro.RequestOverride(req); err != nil {
-			return err
+			return nil, err
 		}
 	}
-	res, err := c.Do(req)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return err
+	return req, nil
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// clientTransport returns the transport the client is going to use, so
+// middleware can wrap it.
+func clientTransport(c *http.Client) http.RoundTripper {
+	if c.Transport != nil {
+		return c.Transport
 	}
-	if len(r.responseFailCodes) > 0 {
-		if coreslices.IntIn(res.StatusCode, r.responseFailCodes) {
-			return fmt.Errorf(res.Status + string(body[:50]))
-		}
+	return http.DefaultTransport
+}
+
+// readRequestBody returns the request body leaving the request readable again.
+func readRequestBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
 	}
-	if len(r.responseOkCodes) > 0 {
-		if !coreslices.IntIn(res.StatusCode, r.responseOkCodes) {
-			return fmt.Errorf(res.Status + string(body[:50]))
+	if r.GetBody != nil {
+		rc, err := r.GetBody()
+		if err != nil {
+			return nil, err
 		}
+		defer rc.Close()
+		return ioutil.ReadAll(rc)
 	}
-	if r.respRaw != nil {
-		*(r.respRaw) = body
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, err
 	}
-	if r.respJson != nil {
-		if err := json.Unmarshal(body, r.respJson); err != nil {
-			return err
-		}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
 	}
-	return nil
+	return body, nil
 }
@@ -0,0 +1,71 @@
+package gorequests
+
+import (
+	"context"
+)
+
+// Future is a request executed in background by ExecAsync. Response targets
+// of the request are safe to read once Done is closed or Wait returned.
+type Future struct {
+	done   chan struct{}
+	err    error
+	cancel context.CancelFunc
+}
+
+// ExecAsync runs Exec on a copy of the request in a new goroutine, so the
+// builder can be reused, a Body stream must not be read until the future is
+// done.
+func (r *requestsInstance) ExecAsync() *Future {
+	instance := r.Clone().(*requestsInstance)
+	ctx := instance.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	instance.ctx = ctx
+	f := &Future{done: make(chan struct{}), cancel: cancel}
+	go func() {
+		defer close(f.done)
+		defer cancel()
+		f.err = instance.Exec()
+	}()
+	return f
+}
+
+// Wait blocks until the request is finished and returns the Exec error.
+func (f *Future) Wait() error {
+	<-f.done
+	return f.err
+}
+
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Err returns the Exec error, or nil while the request is running.
+func (f *Future) Err() error {
+	select {
+	case <-f.done:
+		return f.err
+	default:
+		return nil
+	}
+}
+
+// Cancel cancels the request context, Wait still has to be used to know when
+// the request is finished.
+func (f *Future) Cancel() {
+	f.cancel()
+}
+
+// WaitAll waits for every future and returns the first error in argument
+// order.
+func WaitAll(futures ...*Future) error {
+	var firstErr error
+	for _, f := range futures {
+		if err := f.Wait(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
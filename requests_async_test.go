@@ -0,0 +1,75 @@
+package gorequests
+
+import (
+	"context"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"time"
+)
+
+func (suite *RequestsSuite) TestExecAsync() {
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/books", httpmock.NewStringResponder(http.StatusOK, `["A book"]`))
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/authors", httpmock.NewStringResponder(http.StatusOK, `["John"]`))
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/broken", httpmock.NewStringResponder(http.StatusInternalServerError, ""))
+
+	// Run test target
+	books := make([]string, 0)
+	authors := make([]string, 0)
+	booksFuture := Get("http://localhost/books").ResponseJson(&books).ExecAsync()
+	authorsFuture := Get("http://localhost/authors").ResponseJson(&authors).ExecAsync()
+	brokenFuture := Get("http://localhost/broken").ResponseCodeOk(http.StatusOK).ExecAsync()
+	err := WaitAll(booksFuture, authorsFuture)
+	brokenErr := brokenFuture.Wait()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), []string{"A book"}, books)
+	assert.Equal(suite.T(), []string{"John"}, authors)
+	assert.Error(suite.T(), brokenErr, "should be failed")
+	assert.Equal(suite.T(), brokenErr, brokenFuture.Err())
+	assert.Error(suite.T(), WaitAll(booksFuture, brokenFuture), "should return first error")
+}
+
+func (suite *RequestsSuite) TestExecAsyncCancel() {
+	// Mocking http calls
+	started := make(chan struct{})
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/slow", func(request *http.Request) (*http.Response, error) {
+		close(started)
+		<-request.Context().Done()
+		return nil, request.Context().Err()
+	})
+
+	// Run test target
+	future := Get("http://localhost/slow").Context(context.Background()).ExecAsync()
+	<-started
+	errRunning := future.Err()
+	future.Cancel()
+	select {
+	case <-future.Done():
+	case <-time.After(time.Second):
+		suite.T().Fatal("future should be done after cancel")
+	}
+
+	// Assertions
+	assert.NoError(suite.T(), errRunning, "should not have error while running")
+	assert.ErrorIs(suite.T(), future.Wait(), context.Canceled)
+}
+
+func (suite *RequestsSuite) TestExecAsyncReuse() {
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/books", httpmock.NewStringResponder(http.StatusOK, `["A book"]`))
+
+	// Run test target
+	request := Get("http://localhost/books")
+	errFirst := request.ExecAsync().Wait()
+	errSecond := request.ExecAsync().Wait()
+	errSync := request.Exec()
+
+	// Assertions
+	assert.NoError(suite.T(), errFirst, "should be run without error")
+	assert.NoError(suite.T(), errSecond, "should reuse builder after future is done")
+	assert.NoError(suite.T(), errSync, "should reuse builder for Exec")
+	assert.Equal(suite.T(), 3, httpmock.GetTotalCallCount())
+}
@@ -0,0 +1,251 @@
+package gorequests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+type BatchMode int
+
+const (
+	// BatchCollectAll runs every request and reports all failures.
+	BatchCollectAll BatchMode = iota
+	// BatchFailFast cancels running and pending requests after the first
+	// failure.
+	BatchFailFast
+)
+
+// BatchResult is the outcome of the request with the same index.
+type BatchResult struct {
+	Request  RequestsInstance
+	Err      error
+	Started  bool
+	Duration time.Duration
+}
+
+type BatchStats struct {
+	Total      int
+	Succeeded  int
+	Failed     int
+	Canceled   int
+	Duration   time.Duration
+	MinLatency time.Duration
+	MaxLatency time.Duration
+	AvgLatency time.Duration
+}
+
+// BatchError is returned in BatchCollectAll mode when any request failed,
+// it unwraps to the first failure.
+type BatchError struct {
+	Failed int
+	Total  int
+	First  error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("%d of %d requests failed, first error: %v", e.Failed, e.Total, e.First)
+}
+
+func (e *BatchError) Unwrap() error { return e.First }
+
+// Batch executes many requests with a bounded number of workers.
+type Batch struct {
+	ctx     context.Context
+	workers int
+	perHost int
+	mode    BatchMode
+}
+
+// NewBatch runs 10 requests at a time without a per host limit and collects
+// all errors.
+func NewBatch() *Batch {
+	return &Batch{ctx: context.Background(), workers: 10}
+}
+
+func (b *Batch) Context(ctx context.Context) *Batch {
+	b.ctx = ctx
+	return b
+}
+
+func (b *Batch) Workers(workers int) *Batch {
+	b.workers = workers
+	return b
+}
+
+// PerHost limits concurrent requests to the same host, zero means no limit.
+func (b *Batch) PerHost(limit int) *Batch {
+	b.perHost = limit
+	return b
+}
+
+func (b *Batch) Mode(mode BatchMode) *Batch {
+	b.mode = mode
+	return b
+}
+
+// Run executes the requests and returns results in the same order. Requests
+// must not be shared with other goroutines until Run returns.
+func (b *Batch) Run(requests []RequestsInstance) ([]BatchResult, BatchStats, error) {
+	started := time.Now()
+	ctx, cancel := context.WithCancel(b.ctx)
+	defer cancel()
+	workers := b.workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	results := make([]BatchResult, len(requests))
+	hosts := make([]string, len(requests))
+	pending := make([]int, len(requests))
+	for i, r := range requests {
+		results[i].Request = r
+		hosts[i] = batchHost(r)
+		pending[i] = i
+	}
+
+	var mu sync.Mutex
+	cond := sync.NewCond(&mu)
+	active := 0
+	hostActive := make(map[string]int)
+	var firstErr error
+	wg := sync.WaitGroup{}
+
+	stopWake := make(chan struct{})
+	defer close(stopWake)
+	go func() {
+		select {
+		case <-ctx.Done():
+			mu.Lock()
+			cond.Broadcast()
+			mu.Unlock()
+		case <-stopWake:
+		}
+	}()
+
+	mu.Lock()
+	for len(pending) > 0 && ctx.Err() == nil {
+		next := -1
+		if active < workers {
+			for p, i := range pending {
+				if b.perHost <= 0 || hostActive[hosts[i]] < b.perHost {
+					next = p
+					break
+				}
+			}
+		}
+		if next < 0 {
+			cond.Wait()
+			continue
+		}
+		i := pending[next]
+		pending = append(pending[:next], pending[next+1:]...)
+		active++
+		hostActive[hosts[i]]++
+		results[i].Started = true
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			requestStarted := time.Now()
+			err := batchExec(ctx, requests[i])
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[i].Err = err
+			results[i].Duration = time.Since(requestStarted)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			if err != nil && b.mode == BatchFailFast {
+				cancel()
+			}
+			active--
+			hostActive[hosts[i]]--
+			cond.Broadcast()
+		}(i)
+	}
+	for _, i := range pending {
+		results[i].Err = ctx.Err()
+	}
+	mu.Unlock()
+	wg.Wait()
+
+	stats := batchStats(results, ctx.Err() != nil)
+	stats.Duration = time.Since(started)
+	if firstErr == nil && len(pending) > 0 {
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil && b.mode == BatchCollectAll {
+		return results, stats, &BatchError{Failed: stats.Failed + stats.Canceled, Total: stats.Total, First: firstErr}
+	}
+	return results, stats, firstErr
+}
+
+// batchExec runs a copy of the request with a context canceled together
+// with the batch while keeping the request's own context values and deadline.
+func batchExec(ctx context.Context, r RequestsInstance) error {
+	original, ok := r.(*requestsInstance)
+	if !ok {
+		return r.Exec()
+	}
+	instance := original.Clone().(*requestsInstance)
+	if instance.ctx == nil {
+		instance.ctx = ctx
+		return instance.Exec()
+	}
+	requestCtx, cancel := context.WithCancel(instance.ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-requestCtx.Done():
+		}
+	}()
+	instance.ctx = requestCtx
+	return instance.Exec()
+}
+
+func batchHost(r RequestsInstance) string {
+	if instance, ok := r.(*requestsInstance); ok {
+		if u, err := url.Parse(instance.url); err == nil {
+			return u.Host
+		}
+	}
+	return ""
+}
+
+func batchStats(results []BatchResult, canceled bool) BatchStats {
+	stats := BatchStats{Total: len(results)}
+	var total time.Duration
+	startedCount := 0
+	for _, result := range results {
+		switch {
+		case result.Err == nil:
+			stats.Succeeded++
+		case canceled && (!result.Started || errors.Is(result.Err, context.Canceled)):
+			stats.Canceled++
+		default:
+			stats.Failed++
+		}
+		if !result.Started {
+			continue
+		}
+		if startedCount == 0 || result.Duration < stats.MinLatency {
+			stats.MinLatency = result.Duration
+		}
+		if result.Duration > stats.MaxLatency {
+			stats.MaxLatency = result.Duration
+		}
+		total += result.Duration
+		startedCount++
+	}
+	if startedCount > 0 {
+		stats.AvgLatency = total / time.Duration(startedCount)
+	}
+	return stats
+}
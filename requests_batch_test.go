@@ -0,0 +1,135 @@
+package gorequests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+func (suite *RequestsSuite) TestBatch() {
+	// Test data
+	requests := make([]RequestsInstance, 0)
+	bodies := make([][]byte, 12)
+	for i := range bodies {
+		host := "books.localhost"
+		if i%3 == 0 {
+			host = "authors.localhost"
+		}
+		requests = append(requests, Get("http://%s/items/%d", host, i).ResponseRaw(&bodies[i]))
+	}
+
+	// Mocking http calls
+	var mu sync.Mutex
+	active := make(map[string]int)
+	maxActive := make(map[string]int)
+	maxTotal, total := 0, 0
+	httpmock.RegisterResponder(http.MethodGet, `=~^http://\w+\.localhost/items/\d+$`, func(request *http.Request) (*http.Response, error) {
+		mu.Lock()
+		active[request.URL.Host]++
+		total++
+		if active[request.URL.Host] > maxActive[request.URL.Host] {
+			maxActive[request.URL.Host] = active[request.URL.Host]
+		}
+		if total > maxTotal {
+			maxTotal = total
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		active[request.URL.Host]--
+		total--
+		mu.Unlock()
+		if strings.HasSuffix(request.URL.Path, "/7") {
+			return httpmock.NewStringResponse(http.StatusInternalServerError, ""), nil
+		}
+		return httpmock.NewStringResponse(http.StatusOK, request.URL.Path), nil
+	})
+	for _, r := range requests {
+		r.ResponseCodeOk(http.StatusOK)
+	}
+
+	// Run test target
+	results, stats, err := NewBatch().Workers(4).PerHost(2).Run(requests)
+
+	// Prepare assert stats
+	batchErr := &BatchError{}
+
+	// Assertions
+	if assert.True(suite.T(), errors.As(err, &batchErr), "should collect errors") {
+		assert.Equal(suite.T(), 1, batchErr.Failed)
+	}
+	assert.Equal(suite.T(), 12, stats.Total)
+	assert.Equal(suite.T(), 11, stats.Succeeded)
+	assert.Equal(suite.T(), 1, stats.Failed)
+	assert.True(suite.T(), stats.MaxLatency >= stats.AvgLatency && stats.AvgLatency >= stats.MinLatency)
+	assert.Error(suite.T(), results[7].Err, "should keep result order")
+	for i, body := range bodies {
+		if i != 7 {
+			assert.Equal(suite.T(), fmt.Sprintf("/items/%d", i), string(body))
+		}
+	}
+	assert.LessOrEqual(suite.T(), maxTotal, 4, "should limit workers")
+	assert.LessOrEqual(suite.T(), maxActive["books.localhost"], 2, "should limit requests per host")
+	assert.LessOrEqual(suite.T(), maxActive["authors.localhost"], 2, "should limit requests per host")
+}
+
+func (suite *RequestsSuite) TestBatchFailFast() {
+	// Test data
+	requests := []RequestsInstance{
+		Get("http://localhost/slow").Context(context.Background()),
+		Get("http://localhost/broken").ResponseCodeOk(http.StatusOK),
+		Get("http://localhost/slow"),
+		Get("http://localhost/slow"),
+	}
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/slow", func(request *http.Request) (*http.Response, error) {
+		select {
+		case <-request.Context().Done():
+			return nil, request.Context().Err()
+		case <-time.After(time.Second):
+			return httpmock.NewStringResponse(http.StatusOK, ""), nil
+		}
+	})
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/broken", httpmock.NewStringResponder(http.StatusInternalServerError, ""))
+
+	// Run test target
+	started := time.Now()
+	results, stats, err := NewBatch().Workers(2).Mode(BatchFailFast).Run(requests)
+
+	// Assertions
+	assert.Error(suite.T(), err, "should be failed")
+	assert.False(suite.T(), errors.Is(err, context.Canceled), "should return the first failure")
+	assert.Less(suite.T(), time.Since(started), time.Second, "should cancel running requests")
+	assert.ErrorIs(suite.T(), results[0].Err, context.Canceled)
+	assert.False(suite.T(), results[3].Started, "should not start pending requests")
+	assert.Equal(suite.T(), 1, stats.Failed)
+	assert.Equal(suite.T(), 3, stats.Canceled)
+}
+
+func (suite *RequestsSuite) TestBatchReuse() {
+	// Test data
+	requests := []RequestsInstance{
+		Get("http://localhost/books"),
+		Get("http://localhost/books").Context(context.Background()),
+	}
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/books", httpmock.NewStringResponder(http.StatusOK, ""))
+
+	// Run test target
+	_, _, err := NewBatch().Run(requests)
+	errFirst := requests[0].Exec()
+	errSecond := requests[1].Exec()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.NoError(suite.T(), errFirst, "should reuse request without context after batch")
+	assert.NoError(suite.T(), errSecond, "should reuse request with context after batch")
+}
@@ -0,0 +1,539 @@
+package gorequests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// HeaderFromCache is set on responses served by Cache.
+	HeaderFromCache = "X-From-Cache"
+	// HeaderCacheStale is set on stale responses served by Cache.
+	HeaderCacheStale = "X-Cache-Stale"
+)
+
+type OfflineMode int32
+
+const (
+	// OfflineNever always goes to the network.
+	OfflineNever OfflineMode = iota
+	// OfflineFallback serves the last stored response when the network fails.
+	OfflineFallback
+	// OfflineAlways never goes to the network.
+	OfflineAlways
+)
+
+var ErrNotCached = errors.New("response not cached")
+
+// OfflineError is returned in offline mode when nothing is stored for Key,
+// Err is the network error if the network was tried.
+type OfflineError struct {
+	Key string
+	Err error
+}
+
+func (e *OfflineError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("offline: no cached response for %s: %v", e.Key, e.Err)
+	}
+	return fmt.Sprintf("offline: no cached response for %s", e.Key)
+}
+
+func (e *OfflineError) Unwrap() error { return e.Err }
+
+func (e *OfflineError) Is(target error) bool { return target == ErrNotCached }
+
+// CachedResponse is a stored response with the metadata needed to compute
+// its freshness.
+type CachedResponse struct {
+	Method       string      `json:"method"`
+	Url          string      `json:"url"`
+	StatusCode   int         `json:"statusCode"`
+	Header       http.Header `json:"header"`
+	Body         []byte      `json:"body"`
+	Vary         http.Header `json:"vary,omitempty"`
+	RequestTime  time.Time   `json:"requestTime"`
+	ResponseTime time.Time   `json:"responseTime"`
+}
+
+type CacheStore interface {
+	Get(key string) (*CachedResponse, bool)
+	Set(key string, entry *CachedResponse)
+	Delete(key string)
+}
+
+// Cache is a private RFC 9111 cache middleware.
+type Cache struct {
+	store        CacheStore
+	offline      int32
+	now          func() time.Time
+	mu           sync.Mutex
+	revalidating map[string]bool
+}
+
+func NewCache(store CacheStore) *Cache {
+	return &Cache{
+		store:        store,
+		now:          time.Now,
+		revalidating: make(map[string]bool),
+	}
+}
+
+func (c *Cache) Clock(now func() time.Time) *Cache {
+	c.now = now
+	return c
+}
+
+// Offline switches offline mode, it is safe to call while requests are running.
+func (c *Cache) Offline(mode OfflineMode) *Cache {
+	atomic.StoreInt32(&c.offline, int32(mode))
+	return c
+}
+
+func (c *Cache) Store() CacheStore { return c.store }
+
+func (c *Cache) ClientOverride(client *http.Client) (*http.Client, error) {
+	next := clientTransport(client)
+	client.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return c.roundTrip(next, req)
+	})
+	return client, nil
+}
+
+func CacheKey(r *http.Request) string {
+	return r.Method + " " + r.URL.String()
+}
+
+func (c *Cache) roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	cacheable := req.Method == http.MethodGet || req.Method == http.MethodHead
+	mode := OfflineMode(atomic.LoadInt32(&c.offline))
+	if mode == OfflineAlways && cacheable {
+		return c.offlineResponse(req, nil)
+	}
+	res, err := c.cachedRoundTrip(next, req)
+	if err != nil && mode == OfflineFallback && cacheable && req.Context().Err() == nil {
+		return c.offlineResponse(req, err)
+	}
+	return res, err
+}
+
+// offlineResponse serves the stored response regardless of its freshness.
+func (c *Cache) offlineResponse(req *http.Request, err error) (*http.Response, error) {
+	key := CacheKey(req)
+	entry, ok := c.store.Get(key)
+	if !ok {
+		return nil, &OfflineError{Key: key, Err: err}
+	}
+	return entry.response(req, entry.currentAge(c.now()), true), nil
+}
+
+func (c *Cache) cachedRoundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		res, err := next.RoundTrip(req)
+		if err == nil && res.StatusCode < 400 {
+			c.invalidate(req, res)
+		}
+		return res, err
+	}
+
+	reqCc := parseCacheControl(req.Header.Get("Cache-Control"))
+	if _, ok := reqCc["no-store"]; ok {
+		return next.RoundTrip(req)
+	}
+
+	key := CacheKey(req)
+	entry, ok := c.store.Get(key)
+	if ok && !entry.varyMatch(req) {
+		ok = false
+	}
+	if !ok {
+		if _, ok := reqCc["only-if-cached"]; ok {
+			return newGatewayTimeoutResponse(req), nil
+		}
+		return c.fetch(next, req, key)
+	}
+
+	now := c.now()
+	resCc := parseCacheControl(entry.Header.Get("Cache-Control"))
+	lifetime := entry.freshnessLifetime(resCc)
+	age := entry.currentAge(now)
+	staleness := age - lifetime
+
+	_, reqNoCache := reqCc["no-cache"]
+	_, resNoCache := resCc["no-cache"]
+	noCache := reqNoCache || resNoCache || strings.Contains(req.Header.Get("Pragma"), "no-cache")
+	_, mustRevalidate := resCc["must-revalidate"]
+	if !noCache && c.acceptable(reqCc, age, lifetime, staleness, mustRevalidate) {
+		return entry.response(req, age, staleness > 0), nil
+	}
+	if !noCache && !mustRevalidate && staleness > 0 && staleness <= cacheDirectiveSeconds(resCc, "stale-while-revalidate") {
+		c.revalidateInBackground(next, req, key, entry)
+		return entry.response(req, age, true), nil
+	}
+	if _, ok := reqCc["only-if-cached"]; ok {
+		return newGatewayTimeoutResponse(req), nil
+	}
+
+	res, err := next.RoundTrip(entry.conditionalRequest(req))
+	staleIfError := cacheDirectiveSeconds(resCc, "stale-if-error")
+	if reqStaleIfError := cacheDirectiveSeconds(reqCc, "stale-if-error"); reqStaleIfError > staleIfError {
+		staleIfError = reqStaleIfError
+	}
+	canServeStale := !mustRevalidate && staleIfError >= 0 && staleness <= staleIfError
+	if err != nil {
+		if canServeStale {
+			return entry.response(req, age, staleness > 0), nil
+		}
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotModified {
+		res.Body.Close()
+		entry = c.refresh(key, entry, res)
+		return entry.response(req, 0, false), nil
+	}
+	if res.StatusCode >= http.StatusInternalServerError && canServeStale {
+		res.Body.Close()
+		return entry.response(req, age, staleness > 0), nil
+	}
+	return c.storeResponse(req, key, res, now), nil
+}
+
+// acceptable checks whether a stored response may be served without
+// contacting the origin.
+func (c *Cache) acceptable(reqCc map[string]string, age, lifetime, staleness time.Duration, mustRevalidate bool) bool {
+	if maxAge, ok := reqCc["max-age"]; ok {
+		if seconds, err := strconv.Atoi(maxAge); err == nil && age > time.Duration(seconds)*time.Second {
+			return false
+		}
+	}
+	if minFresh, ok := reqCc["min-fresh"]; ok {
+		if seconds, err := strconv.Atoi(minFresh); err == nil && lifetime-age < time.Duration(seconds)*time.Second {
+			return false
+		}
+	}
+	if staleness <= 0 {
+		return true
+	}
+	if maxStale, ok := reqCc["max-stale"]; ok && !mustRevalidate {
+		if len(maxStale) == 0 {
+			return true
+		}
+		if seconds, err := strconv.Atoi(maxStale); err == nil && staleness <= time.Duration(seconds)*time.Second {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cache) fetch(next http.RoundTripper, req *http.Request, key string) (*http.Response, error) {
+	requestTime := c.now()
+	res, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	return c.storeResponse(req, key, res, requestTime), nil
+}
+
+// storeResponse stores a cacheable response once its body is read to the end.
+func (c *Cache) storeResponse(req *http.Request, key string, res *http.Response, requestTime time.Time) *http.Response {
+	if !isCacheable(req, res) {
+		return res
+	}
+	entry := &CachedResponse{
+		Method:       req.Method,
+		Url:          req.URL.String(),
+		StatusCode:   res.StatusCode,
+		Header:       res.Header.Clone(),
+		Vary:         varyHeader(req, res),
+		RequestTime:  requestTime,
+		ResponseTime: c.now(),
+	}
+	res.Body = &cachingReadCloser{
+		ReadCloser: res.Body,
+		onEOF: func(body []byte) {
+			entry.Body = body
+			c.store.Set(key, entry)
+		},
+	}
+	return res
+}
+
+func (c *Cache) refresh(key string, entry *CachedResponse, notModified *http.Response) *CachedResponse {
+	updated := *entry
+	updated.Header = entry.Header.Clone()
+	for k, vv := range notModified.Header {
+		if k == "Content-Length" || k == "Content-Encoding" || k == "Transfer-Encoding" {
+			continue
+		}
+		updated.Header[k] = vv
+	}
+	updated.RequestTime = c.now()
+	updated.ResponseTime = c.now()
+	c.store.Set(key, &updated)
+	return &updated
+}
+
+func (c *Cache) revalidateInBackground(next http.RoundTripper, req *http.Request, key string, entry *CachedResponse) {
+	c.mu.Lock()
+	if c.revalidating[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.revalidating[key] = true
+	c.mu.Unlock()
+
+	bgReq := entry.conditionalRequest(req.Clone(context.Background()))
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.revalidating, key)
+			c.mu.Unlock()
+		}()
+		requestTime := c.now()
+		res, err := next.RoundTrip(bgReq)
+		if err != nil {
+			return
+		}
+		defer res.Body.Close()
+		if res.StatusCode == http.StatusNotModified {
+			c.refresh(key, entry, res)
+			return
+		}
+		res = c.storeResponse(req, key, res, requestTime)
+		_, _ = io.Copy(ioutil.Discard, res.Body)
+	}()
+}
+
+func (c *Cache) invalidate(req *http.Request, res *http.Response) {
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		c.store.Delete(method + " " + req.URL.String())
+		for _, header := range []string{"Location", "Content-Location"} {
+			if location, err := req.URL.Parse(res.Header.Get(header)); err == nil && len(res.Header.Get(header)) != 0 && location.Host == req.URL.Host {
+				c.store.Delete(method + " " + location.String())
+			}
+		}
+	}
+}
+
+func (e *CachedResponse) response(req *http.Request, age time.Duration, stale bool) *http.Response {
+	header := e.Header.Clone()
+	header.Set("Age", strconv.Itoa(int(age/time.Second)))
+	header.Set(HeaderFromCache, "1")
+	if stale {
+		header.Set(HeaderCacheStale, "1")
+	}
+	body := e.Body
+	if req.Method == http.MethodHead {
+		body = nil
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func (e *CachedResponse) conditionalRequest(req *http.Request) *http.Request {
+	etag := e.Header.Get("ETag")
+	lastModified := e.Header.Get("Last-Modified")
+	if len(etag) == 0 && len(lastModified) == 0 {
+		return req
+	}
+	req = req.Clone(req.Context())
+	if len(etag) != 0 {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if len(lastModified) != 0 {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+	return req
+}
+
+func (e *CachedResponse) varyMatch(req *http.Request) bool {
+	for name, values := range e.Vary {
+		if strings.Join(req.Header.Values(name), ", ") != strings.Join(values, ", ") {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *CachedResponse) freshnessLifetime(cc map[string]string) time.Duration {
+	if maxAge, ok := cc["max-age"]; ok {
+		if seconds, err := strconv.Atoi(maxAge); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+		return 0
+	}
+	date := e.date()
+	if expires := e.Header.Get("Expires"); len(expires) != 0 {
+		t, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+		return t.Sub(date)
+	}
+	if lastModified, err := http.ParseTime(e.Header.Get("Last-Modified")); err == nil && heuristicallyCacheable(e.StatusCode) {
+		if lifetime := date.Sub(lastModified) / 10; lifetime > 0 {
+			if lifetime > 24*time.Hour {
+				return 24 * time.Hour
+			}
+			return lifetime
+		}
+	}
+	return 0
+}
+
+// currentAge follows RFC 9111 section 4.2.3.
+func (e *CachedResponse) currentAge(now time.Time) time.Duration {
+	apparentAge := e.ResponseTime.Sub(e.date())
+	if apparentAge < 0 {
+		apparentAge = 0
+	}
+	ageValue := time.Duration(0)
+	if seconds, err := strconv.Atoi(e.Header.Get("Age")); err == nil {
+		ageValue = time.Duration(seconds) * time.Second
+	}
+	correctedAge := ageValue + e.ResponseTime.Sub(e.RequestTime)
+	if apparentAge > correctedAge {
+		correctedAge = apparentAge
+	}
+	return correctedAge + now.Sub(e.ResponseTime)
+}
+
+func (e *CachedResponse) date() time.Time {
+	if date, err := http.ParseTime(e.Header.Get("Date")); err == nil {
+		return date
+	}
+	return e.ResponseTime
+}
+
+func isCacheable(req *http.Request, res *http.Response) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	if _, ok := parseCacheControl(req.Header.Get("Cache-Control"))["no-store"]; ok {
+		return false
+	}
+	cc := parseCacheControl(res.Header.Get("Cache-Control"))
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	if strings.TrimSpace(res.Header.Get("Vary")) == "*" {
+		return false
+	}
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent, http.StatusMultipleChoices,
+		http.StatusMovedPermanently, http.StatusPermanentRedirect, http.StatusNotFound, http.StatusMethodNotAllowed,
+		http.StatusGone, http.StatusRequestURITooLong, http.StatusNotImplemented:
+	default:
+		_, public := cc["public"]
+		_, maxAge := cc["max-age"]
+		if !public && !maxAge && len(res.Header.Get("Expires")) == 0 {
+			return false
+		}
+	}
+	for _, header := range []string{"ETag", "Last-Modified", "Expires"} {
+		if len(res.Header.Get(header)) != 0 {
+			return true
+		}
+	}
+	_, maxAge := cc["max-age"]
+	_, noCache := cc["no-cache"]
+	return maxAge || noCache
+}
+
+func heuristicallyCacheable(status int) bool {
+	switch status {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent, http.StatusMultipleChoices,
+		http.StatusMovedPermanently, http.StatusPermanentRedirect, http.StatusNotFound, http.StatusMethodNotAllowed,
+		http.StatusGone, http.StatusRequestURITooLong, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+func varyHeader(req *http.Request, res *http.Response) http.Header {
+	vary := http.Header{}
+	for _, value := range res.Header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); len(name) != 0 {
+				vary[http.CanonicalHeaderKey(name)] = req.Header.Values(name)
+			}
+		}
+	}
+	if len(vary) == 0 {
+		return nil
+	}
+	return vary
+}
+
+func parseCacheControl(value string) map[string]string {
+	cc := make(map[string]string)
+	for _, directive := range strings.Split(value, ",") {
+		directive = strings.TrimSpace(directive)
+		if len(directive) == 0 {
+			continue
+		}
+		name, arg, _ := strings.Cut(directive, "=")
+		cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(arg), `"`)
+	}
+	return cc
+}
+
+func cacheDirectiveSeconds(cc map[string]string, name string) time.Duration {
+	if value, ok := cc[name]; ok {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return -1
+}
+
+func newGatewayTimeoutResponse(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:     "504 " + http.StatusText(http.StatusGatewayTimeout),
+		StatusCode: http.StatusGatewayTimeout,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
+	}
+}
+
+// cachingReadCloser buffers the body while it is read and hands it over to
+// the cache once the end of the body is reached.
+type cachingReadCloser struct {
+	io.ReadCloser
+	buf   bytes.Buffer
+	onEOF func(body []byte)
+	done  bool
+}
+
+func (r *cachingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.buf.Write(p[:n])
+	if err == io.EOF && !r.done {
+		r.done = true
+		r.onEOF(r.buf.Bytes())
+	}
+	return n, err
+}
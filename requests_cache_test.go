@@ -0,0 +1,311 @@
+package gorequests
+
+import (
+	"errors"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"time"
+)
+
+func (suite *RequestsSuite) TestCacheFresh() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/books/1"
+	callKey := method + " " + reqUrl
+	cache := NewCache(NewMemoryCacheStore(10))
+
+	// Mocking http calls
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		response := httpmock.NewStringResponse(http.StatusOK, `{"title":"A book"}`)
+		response.Header.Set("Cache-Control", "max-age=60")
+		return response, nil
+	})
+
+	// Run test target
+	results := make([]map[string]string, 2)
+	errs := make([]error, 2)
+	for i := range results {
+		errs[i] = Get(reqUrl).Use(cache).ResponseJson(&results[i]).Exec()
+	}
+
+	// Prepare assert stats
+	httpStats := httpmock.GetCallCountInfo()
+
+	// Assertions
+	assert.Equal(suite.T(), []error{nil, nil}, errs, "should be run without error")
+	assert.Equal(suite.T(), 1, httpStats[callKey], "should be call once")
+	assert.Equal(suite.T(), results[0], results[1], "should be filled from cache")
+}
+
+func (suite *RequestsSuite) TestCacheRevalidate() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/books/1"
+	callKey := method + " " + reqUrl
+	cache := NewCache(NewMemoryCacheStore(10))
+
+	// Mocking http calls
+	conditional := 0
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			response := httpmock.NewStringResponse(http.StatusNotModified, "")
+			response.Header.Set("ETag", `"v1"`)
+			return response, nil
+		}
+		response := httpmock.NewStringResponse(http.StatusOK, `{"title":"A book"}`)
+		response.Header.Set("Cache-Control", "no-cache")
+		response.Header.Set("ETag", `"v1"`)
+		return response, nil
+	})
+
+	// Run test target
+	results := make([]map[string]string, 2)
+	errs := make([]error, 2)
+	for i := range results {
+		errs[i] = Get(reqUrl).Use(cache).ResponseJson(&results[i]).Exec()
+	}
+
+	// Prepare assert stats
+	httpStats := httpmock.GetCallCountInfo()
+
+	// Assertions
+	assert.Equal(suite.T(), []error{nil, nil}, errs, "should be run without error")
+	assert.Equal(suite.T(), 2, httpStats[callKey], "should be call twice")
+	assert.Equal(suite.T(), 1, conditional, "should revalidate with etag")
+	assert.Equal(suite.T(), map[string]string{"title": "A book"}, results[1], "should be filled from cache")
+}
+
+func (suite *RequestsSuite) TestCacheVary() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/books/1"
+	callKey := method + " " + reqUrl
+	cache := NewCache(NewMemoryCacheStore(10))
+
+	// Mocking http calls
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		response := httpmock.NewStringResponse(http.StatusOK, request.Header.Get("Accept-Language"))
+		response.Header.Set("Cache-Control", "max-age=60")
+		response.Header.Set("Vary", "Accept-Language")
+		return response, nil
+	})
+
+	// Run test target
+	var en, enCached, de []byte
+	_ = Get(reqUrl).Use(cache).Header("Accept-Language", "en").ResponseRaw(&en).Exec()
+	_ = Get(reqUrl).Use(cache).Header("Accept-Language", "en").ResponseRaw(&enCached).Exec()
+	_ = Get(reqUrl).Use(cache).Header("Accept-Language", "de").ResponseRaw(&de).Exec()
+
+	// Prepare assert stats
+	httpStats := httpmock.GetCallCountInfo()
+
+	// Assertions
+	assert.Equal(suite.T(), 2, httpStats[callKey], "should be call twice")
+	assert.Equal(suite.T(), "en", string(enCached), "should be served from cache")
+	assert.Equal(suite.T(), "de", string(de), "should not reuse other variant")
+}
+
+func (suite *RequestsSuite) TestCacheStaleIfError() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/books/1"
+	callKey := method + " " + reqUrl
+	now := time.Now()
+	cache := NewCache(NewMemoryCacheStore(10)).Clock(func() time.Time { return now })
+	status := http.StatusOK
+
+	// Mocking http calls
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		response := httpmock.NewStringResponse(status, `SUCCESS`)
+		response.Header.Set("Cache-Control", "max-age=10, stale-if-error=60")
+		return response, nil
+	})
+
+	// Run test target
+	var first, second []byte
+	firstErr := Get(reqUrl).Use(cache).ResponseRaw(&first).Exec()
+	now = now.Add(30 * time.Second)
+	status = http.StatusInternalServerError
+	secondErr := Get(reqUrl).Use(cache).ResponseCodeOk(http.StatusOK).ResponseRaw(&second).Exec()
+
+	// Prepare assert stats
+	httpStats := httpmock.GetCallCountInfo()
+
+	// Assertions
+	assert.NoError(suite.T(), firstErr, "should be run without error")
+	assert.NoError(suite.T(), secondErr, "should serve stale response")
+	assert.Equal(suite.T(), 2, httpStats[callKey], "should be call twice")
+	assert.Equal(suite.T(), first, second, "should be served from cache")
+}
+
+func (suite *RequestsSuite) TestCacheStaleWhileRevalidate() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/books/1"
+	callKey := method + " " + reqUrl
+	now := time.Now()
+	store := NewMemoryCacheStore(10)
+	cache := NewCache(store).Clock(func() time.Time { return now })
+	body := "v1"
+
+	// Mocking http calls
+	revalidated := make(chan struct{}, 1)
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		response := httpmock.NewStringResponse(http.StatusOK, body)
+		response.Header.Set("Cache-Control", "max-age=10, stale-while-revalidate=60")
+		if body == "v2" {
+			revalidated <- struct{}{}
+		}
+		return response, nil
+	})
+
+	// Run test target
+	var first, second []byte
+	_ = Get(reqUrl).Use(cache).ResponseRaw(&first).Exec()
+	now = now.Add(30 * time.Second)
+	body = "v2"
+	err := Get(reqUrl).Use(cache).ResponseRaw(&second).Exec()
+	<-revalidated
+	time.Sleep(10 * time.Millisecond)
+	entry, _ := store.Get(http.MethodGet + " " + reqUrl)
+
+	// Prepare assert stats
+	httpStats := httpmock.GetCallCountInfo()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), 2, httpStats[callKey], "should be call twice")
+	assert.Equal(suite.T(), "v1", string(second), "should serve stale response")
+	assert.Equal(suite.T(), "v2", string(entry.Body), "should update cache in background")
+}
+
+func (suite *RequestsSuite) TestCacheInvalidate() {
+	// Test data
+	reqUrl := "http://localhost/books/1"
+	callKey := http.MethodGet + " " + reqUrl
+	cache := NewCache(NewMemoryCacheStore(10))
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, reqUrl, func(request *http.Request) (*http.Response, error) {
+		response := httpmock.NewStringResponse(http.StatusOK, "")
+		response.Header.Set("Cache-Control", "max-age=60")
+		return response, nil
+	})
+	httpmock.RegisterResponder(http.MethodPut, reqUrl, httpmock.NewStringResponder(http.StatusNoContent, ""))
+
+	// Run test target
+	_ = Get(reqUrl).Use(cache).Exec()
+	_ = Put(reqUrl).Use(cache).Exec()
+	_ = Get(reqUrl).Use(cache).Exec()
+
+	// Prepare assert stats
+	httpStats := httpmock.GetCallCountInfo()
+
+	// Assertions
+	assert.Equal(suite.T(), 2, httpStats[callKey], "should refetch after update")
+}
+
+func (suite *RequestsSuite) TestMemoryCacheStoreEviction() {
+	// Test data
+	store := NewMemoryCacheStore(2)
+
+	// Run test target
+	store.Set("a", &CachedResponse{Url: "a"})
+	store.Set("b", &CachedResponse{Url: "b"})
+	store.Get("a")
+	store.Set("c", &CachedResponse{Url: "c"})
+	_, okA := store.Get("a")
+	_, okB := store.Get("b")
+
+	// Assertions
+	assert.True(suite.T(), okA, "should keep recently used entry")
+	assert.False(suite.T(), okB, "should evict least recently used entry")
+	assert.Equal(suite.T(), 2, store.Len())
+}
+
+func (suite *RequestsSuite) TestDiskCacheStore() {
+	// Test data
+	store := NewDiskCacheStore(suite.T().TempDir())
+	entry := &CachedResponse{Url: "http://localhost", StatusCode: http.StatusOK, Header: http.Header{"Etag": {`"v1"`}}, Body: []byte("body")}
+
+	// Run test target
+	store.Set("key", entry)
+	actual, ok := store.Get("key")
+	store.Delete("key")
+	_, okDeleted := store.Get("key")
+
+	// Assertions
+	assert.True(suite.T(), ok, "should be stored")
+	assert.Equal(suite.T(), entry, actual, "should be equal entry")
+	assert.False(suite.T(), okDeleted, "should be deleted")
+}
+
+func (suite *RequestsSuite) TestCacheOfflineFallback() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/books/1"
+	missingUrl := "http://localhost/books/2"
+	cache := NewCache(NewMemoryCacheStore(10)).Offline(OfflineFallback)
+	online := true
+
+	// Mocking http calls
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		if !online {
+			return httpmock.ConnectionFailure(request)
+		}
+		response := httpmock.NewStringResponse(http.StatusOK, `SUCCESS`)
+		response.Header.Set("ETag", `"v1"`)
+		response.Header.Set("Cache-Control", "no-cache")
+		return response, nil
+	})
+	httpmock.RegisterResponder(method, missingUrl, httpmock.ConnectionFailure)
+
+	// Run test target
+	var first, second []byte
+	firstMeta, secondMeta := ResponseMeta{}, ResponseMeta{}
+	firstErr := Get(reqUrl).Use(cache).ResponseRaw(&first).ResponseMeta(&firstMeta).Exec()
+	online = false
+	secondErr := Get(reqUrl).Use(cache).ResponseRaw(&second).ResponseMeta(&secondMeta).Exec()
+	missingErr := Get(missingUrl).Use(cache).Exec()
+	offlineErr := new(OfflineError)
+
+	// Assertions
+	assert.NoError(suite.T(), firstErr, "should be run without error")
+	assert.NoError(suite.T(), secondErr, "should serve cached response")
+	assert.Equal(suite.T(), first, second, "should be served from cache")
+	assert.False(suite.T(), firstMeta.Stale, "should not be stale")
+	assert.True(suite.T(), secondMeta.FromCache, "should be from cache")
+	assert.True(suite.T(), secondMeta.Stale, "should be marked stale")
+	assert.ErrorIs(suite.T(), missingErr, ErrNotCached, "should fail without cached response")
+	assert.True(suite.T(), errors.As(missingErr, &offlineErr), "should be typed error")
+	assert.Equal(suite.T(), method+" "+missingUrl, offlineErr.Key)
+}
+
+func (suite *RequestsSuite) TestCacheOfflineAlways() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/books/1"
+	callKey := method + " " + reqUrl
+	store := NewMemoryCacheStore(10)
+	store.Set(callKey, &CachedResponse{StatusCode: http.StatusOK, Header: http.Header{}, Body: []byte(`SUCCESS`)})
+	cache := NewCache(store).Offline(OfflineAlways)
+
+	// Mocking http calls
+	httpmock.RegisterResponder(method, reqUrl, httpmock.NewStringResponder(http.StatusOK, `NETWORK`))
+
+	// Run test target
+	var actual []byte
+	meta := ResponseMeta{}
+	err := Get(reqUrl).Use(cache).ResponseRaw(&actual).ResponseMeta(&meta).Exec()
+
+	// Prepare assert stats
+	httpStats := httpmock.GetCallCountInfo()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), 0, httpStats[callKey], "should not send http request")
+	assert.Equal(suite.T(), "SUCCESS", string(actual), "should be served from cache")
+	assert.True(suite.T(), meta.Stale, "should be marked stale")
+}
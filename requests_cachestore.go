@@ -0,0 +1,130 @@
+package gorequests
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// MemoryCacheStore keeps up to maxEntries responses, evicting the least
+// recently used one.
+type MemoryCacheStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	items      map[string]*list.Element
+	lru        *list.List
+}
+
+type memoryCacheItem struct {
+	key   string
+	entry *CachedResponse
+}
+
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	return &MemoryCacheStore{
+		maxEntries: maxEntries,
+		items:      make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func (s *MemoryCacheStore) Get(key string) (*CachedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[key]; ok {
+		s.lru.MoveToFront(el)
+		return el.Value.(*memoryCacheItem).entry, true
+	}
+	return nil, false
+}
+
+func (s *MemoryCacheStore) Set(key string, entry *CachedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[key]; ok {
+		el.Value.(*memoryCacheItem).entry = entry
+		s.lru.MoveToFront(el)
+		return
+	}
+	s.items[key] = s.lru.PushFront(&memoryCacheItem{key: key, entry: entry})
+	for s.maxEntries > 0 && s.lru.Len() > s.maxEntries {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.items, oldest.Value.(*memoryCacheItem).key)
+	}
+}
+
+func (s *MemoryCacheStore) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.items[key]; ok {
+		s.lru.Remove(el)
+		delete(s.items, key)
+	}
+}
+
+func (s *MemoryCacheStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lru.Len()
+}
+
+// DiskCacheStore keeps every response as a json file in dir.
+type DiskCacheStore struct {
+	dir string
+}
+
+func NewDiskCacheStore(dir string) *DiskCacheStore {
+	return &DiskCacheStore{dir: dir}
+}
+
+func (s *DiskCacheStore) Get(key string) (*CachedResponse, bool) {
+	data, err := ioutil.ReadFile(s.path(key))
+	if err != nil {
+		return nil, false
+	}
+	entry := new(CachedResponse)
+	if err := json.Unmarshal(data, entry); err != nil {
+		return nil, false
+	}
+	return entry, true
+}
+
+func (s *DiskCacheStore) Set(key string, entry *CachedResponse) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return
+	}
+	tmp, err := ioutil.TempFile(s.dir, "tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func (s *DiskCacheStore) Delete(key string) {
+	os.Remove(s.path(key))
+}
+
+func (s *DiskCacheStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
@@ -0,0 +1,296 @@
+package gorequests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned without sending the request while the
+// circuit for Key is open or the half-open probes are exhausted.
+type CircuitOpenError struct {
+	Key        string
+	State      CircuitState
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker for %q is %s, retry after %s", e.Key, e.State, e.RetryAfter)
+}
+
+func (e *CircuitOpenError) Is(target error) bool { return target == ErrCircuitOpen }
+
+type CircuitBreaker struct {
+	mu                  sync.Mutex
+	key                 func(r *http.Request) string
+	consecutiveFailures int
+	failureRatio        float64
+	minRequests         int
+	window              time.Duration
+	openTimeout         time.Duration
+	halfOpenRequests    int
+	isFailure           func(res *http.Response, err error) bool
+	onStateChange       []func(key string, from, to CircuitState)
+	circuits            map[string]*circuit
+	now                 func() time.Time
+}
+
+type circuit struct {
+	state               CircuitState
+	generation          uint64
+	openedAt            time.Time
+	windowStart         time.Time
+	requests            int
+	failures            int
+	consecutiveFailures int
+	halfOpenInFlight    int
+	halfOpenSuccesses   int
+}
+
+type stateChange struct {
+	key      string
+	from, to CircuitState
+}
+
+// NewCircuitBreaker opens a per host circuit after 5 consecutive failures
+// and lets a single probe through after 30 seconds.
+func NewCircuitBreaker() *CircuitBreaker {
+	b := &CircuitBreaker{
+		consecutiveFailures: 5,
+		window:              time.Minute,
+		openTimeout:         30 * time.Second,
+		halfOpenRequests:    1,
+		isFailure: func(res *http.Response, err error) bool {
+			return err != nil || res.StatusCode >= http.StatusInternalServerError
+		},
+		circuits: make(map[string]*circuit),
+		now:      time.Now,
+	}
+	return b.PerHost()
+}
+
+func (b *CircuitBreaker) PerHost() *CircuitBreaker {
+	return b.PerKey(func(r *http.Request) string { return r.URL.Host })
+}
+
+func (b *CircuitBreaker) PerKey(key func(r *http.Request) string) *CircuitBreaker {
+	b.key = key
+	return b
+}
+
+func (b *CircuitBreaker) Global() *CircuitBreaker {
+	return b.PerKey(func(r *http.Request) string { return "" })
+}
+
+// ConsecutiveFailures trips the circuit after n failures in a row, zero
+// disables the threshold.
+func (b *CircuitBreaker) ConsecutiveFailures(n int) *CircuitBreaker {
+	b.consecutiveFailures = n
+	return b
+}
+
+// FailureRatio trips the circuit when the share of failed requests in the
+// current window reaches ratio, once at least minRequests were sent.
+func (b *CircuitBreaker) FailureRatio(ratio float64, minRequests int) *CircuitBreaker {
+	b.failureRatio = ratio
+	b.minRequests = minRequests
+	return b
+}
+
+// Window sets how often closed circuit counters are reset, zero keeps them
+// until the next state change.
+func (b *CircuitBreaker) Window(window time.Duration) *CircuitBreaker {
+	b.window = window
+	return b
+}
+
+func (b *CircuitBreaker) OpenTimeout(timeout time.Duration) *CircuitBreaker {
+	b.openTimeout = timeout
+	return b
+}
+
+// HalfOpenRequests sets how many probes are let through in half-open state,
+// all of them must succeed to close the circuit.
+func (b *CircuitBreaker) HalfOpenRequests(n int) *CircuitBreaker {
+	if n < 1 {
+		n = 1
+	}
+	b.halfOpenRequests = n
+	return b
+}
+
+func (b *CircuitBreaker) FailureWhen(isFailure func(res *http.Response, err error) bool) *CircuitBreaker {
+	b.isFailure = isFailure
+	return b
+}
+
+func (b *CircuitBreaker) OnStateChange(callback func(key string, from, to CircuitState)) *CircuitBreaker {
+	b.onStateChange = append(b.onStateChange, callback)
+	return b
+}
+
+func (b *CircuitBreaker) State(key string) CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuit(key)
+	if c.state == CircuitOpen && !b.now().Before(c.openedAt.Add(b.openTimeout)) {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+func (b *CircuitBreaker) ClientOverride(c *http.Client) (*http.Client, error) {
+	next := clientTransport(c)
+	c.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		key := b.key(req)
+		generation, err := b.before(key)
+		if err != nil {
+			return nil, err
+		}
+		res, err := next.RoundTrip(req)
+		if err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) && req.Context().Err() != nil {
+			b.after(key, generation, false, true)
+		} else {
+			b.after(key, generation, b.isFailure(res, err), false)
+		}
+		return res, err
+	})
+	return c, nil
+}
+
+func (b *CircuitBreaker) before(key string) (uint64, error) {
+	b.mu.Lock()
+	changes := make([]stateChange, 0)
+	defer func() {
+		b.mu.Unlock()
+		b.notify(changes)
+	}()
+
+	now := b.now()
+	c := b.circuit(key)
+	if c.state == CircuitOpen {
+		if retryAfter := c.openedAt.Add(b.openTimeout).Sub(now); retryAfter > 0 {
+			return 0, &CircuitOpenError{Key: key, State: CircuitOpen, RetryAfter: retryAfter}
+		}
+		changes = append(changes, b.setState(key, c, CircuitHalfOpen, now))
+	}
+	switch c.state {
+	case CircuitHalfOpen:
+		if c.halfOpenInFlight >= b.halfOpenRequests {
+			return 0, &CircuitOpenError{Key: key, State: CircuitHalfOpen}
+		}
+		c.halfOpenInFlight++
+	case CircuitClosed:
+		if b.window > 0 && now.Sub(c.windowStart) >= b.window {
+			c.resetCounts(now)
+		}
+	}
+	return c.generation, nil
+}
+
+func (b *CircuitBreaker) after(key string, generation uint64, failed, ignored bool) {
+	b.mu.Lock()
+	changes := make([]stateChange, 0)
+	defer func() {
+		b.mu.Unlock()
+		b.notify(changes)
+	}()
+
+	now := b.now()
+	c := b.circuit(key)
+	if c.generation != generation {
+		return
+	}
+	switch c.state {
+	case CircuitClosed:
+		if ignored {
+			return
+		}
+		c.requests++
+		if !failed {
+			c.consecutiveFailures = 0
+			return
+		}
+		c.failures++
+		c.consecutiveFailures++
+		if (b.consecutiveFailures > 0 && c.consecutiveFailures >= b.consecutiveFailures) ||
+			(b.failureRatio > 0 && c.requests >= b.minRequests && float64(c.failures)/float64(c.requests) >= b.failureRatio) {
+			changes = append(changes, b.setState(key, c, CircuitOpen, now))
+		}
+	case CircuitHalfOpen:
+		c.halfOpenInFlight--
+		if ignored {
+			return
+		}
+		if failed {
+			changes = append(changes, b.setState(key, c, CircuitOpen, now))
+			return
+		}
+		c.halfOpenSuccesses++
+		if c.halfOpenSuccesses >= b.halfOpenRequests {
+			changes = append(changes, b.setState(key, c, CircuitClosed, now))
+		}
+	}
+}
+
+func (b *CircuitBreaker) circuit(key string) *circuit {
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{windowStart: b.now()}
+		b.circuits[key] = c
+	}
+	return c
+}
+
+func (b *CircuitBreaker) setState(key string, c *circuit, state CircuitState, now time.Time) stateChange {
+	change := stateChange{key: key, from: c.state, to: state}
+	c.state = state
+	c.generation++
+	c.halfOpenInFlight = 0
+	c.halfOpenSuccesses = 0
+	c.resetCounts(now)
+	if state == CircuitOpen {
+		c.openedAt = now
+	}
+	return change
+}
+
+func (b *CircuitBreaker) notify(changes []stateChange) {
+	for _, change := range changes {
+		for _, callback := range b.onStateChange {
+			callback(change.key, change.from, change.to)
+		}
+	}
+}
+
+func (c *circuit) resetCounts(now time.Time) {
+	c.windowStart = now
+	c.requests = 0
+	c.failures = 0
+	c.consecutiveFailures = 0
+}
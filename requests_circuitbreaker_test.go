@@ -0,0 +1,82 @@
+package gorequests
+
+import (
+	"errors"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"time"
+)
+
+func (suite *RequestsSuite) TestCircuitBreaker() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/health"
+	callKey := method + " " + reqUrl
+	transitions := make([]string, 0)
+	breaker := NewCircuitBreaker().
+		ConsecutiveFailures(2).
+		OpenTimeout(30 * time.Millisecond).
+		OnStateChange(func(key string, from, to CircuitState) {
+			transitions = append(transitions, key+": "+from.String()+" -> "+to.String())
+		})
+	status := http.StatusServiceUnavailable
+
+	// Mocking http calls
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		return httpmock.NewStringResponse(status, ""), nil
+	})
+
+	// Run test target
+	for i := 0; i < 2; i++ {
+		_ = Get(reqUrl).Use(breaker).Exec()
+	}
+	openErr := Get(reqUrl).Use(breaker).Exec()
+	openCalls := httpmock.GetCallCountInfo()[callKey]
+	time.Sleep(40 * time.Millisecond)
+	status = http.StatusOK
+	probeErr := Get(reqUrl).Use(breaker).Exec()
+
+	// Prepare assert stats
+	httpStats := httpmock.GetCallCountInfo()
+	openCircuitErr := new(CircuitOpenError)
+
+	// Assertions
+	assert.ErrorIs(suite.T(), openErr, ErrCircuitOpen, "should fail fast")
+	assert.True(suite.T(), errors.As(openErr, &openCircuitErr), "should be typed error")
+	assert.Equal(suite.T(), "localhost", openCircuitErr.Key, "should be scoped per host")
+	assert.Equal(suite.T(), 2, openCalls, "should not send request while open")
+	assert.NoError(suite.T(), probeErr, "should let probe through")
+	assert.Equal(suite.T(), 3, httpStats[callKey], "should be call three times")
+	assert.Equal(suite.T(), CircuitClosed, breaker.State("localhost"), "should be closed after probe")
+	assert.Equal(suite.T(), []string{
+		"localhost: closed -> open",
+		"localhost: open -> half-open",
+		"localhost: half-open -> closed",
+	}, transitions)
+}
+
+func (suite *RequestsSuite) TestCircuitBreakerFailureRatio() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/health"
+	breaker := NewCircuitBreaker().ConsecutiveFailures(0).FailureRatio(0.5, 4)
+	statuses := []int{http.StatusOK, http.StatusInternalServerError, http.StatusOK, http.StatusBadGateway}
+
+	// Mocking http calls
+	calls := 0
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		calls++
+		return httpmock.NewStringResponse(statuses[(calls-1)%len(statuses)], ""), nil
+	})
+
+	// Run test target
+	states := make([]CircuitState, 0)
+	for range statuses {
+		_ = Get(reqUrl).Use(breaker).Exec()
+		states = append(states, breaker.State("localhost"))
+	}
+
+	// Assertions
+	assert.Equal(suite.T(), []CircuitState{CircuitClosed, CircuitClosed, CircuitClosed, CircuitOpen}, states)
+}
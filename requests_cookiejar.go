@@ -0,0 +1,413 @@
+package gorequests
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"golang.org/x/net/publicsuffix"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type CookieFormat int
+
+const (
+	CookieFormatJson CookieFormat = iota
+	CookieFormatNetscape
+)
+
+// CookieJar is a http.CookieJar which keeps every cookie attribute so the
+// jar can be saved to and loaded from disk.
+type CookieJar struct {
+	mu         sync.Mutex
+	psList     cookiejar.PublicSuffixList
+	entries    map[string]*jarEntry
+	now        func() time.Time
+	nextSeqNum uint64
+}
+
+type jarEntry struct {
+	Name       string    `json:"name"`
+	Value      string    `json:"value"`
+	Domain     string    `json:"domain"`
+	Path       string    `json:"path"`
+	Expires    time.Time `json:"expires"`
+	Persistent bool      `json:"persistent"`
+	HostOnly   bool      `json:"hostOnly"`
+	Secure     bool      `json:"secure"`
+	HttpOnly   bool      `json:"httpOnly"`
+	SameSite   string    `json:"sameSite,omitempty"`
+	Creation   time.Time `json:"creation"`
+
+	seqNum uint64
+}
+
+func NewCookieJar() *CookieJar {
+	return &CookieJar{
+		psList:  publicsuffix.List,
+		entries: make(map[string]*jarEntry),
+		now:     time.Now,
+	}
+}
+
+// PublicSuffixList replaces the public suffix list used to reject cookies
+// set for domains like "co.uk", nil disables the check.
+func (j *CookieJar) PublicSuffixList(list cookiejar.PublicSuffixList) *CookieJar {
+	j.psList = list
+	return j
+}
+
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "ws" && u.Scheme != "wss" {
+		return
+	}
+	host, err := canonicalHost(u.Host)
+	if err != nil {
+		return
+	}
+	defPath := defaultCookiePath(u.Path)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := j.now()
+	for _, cookie := range cookies {
+		domain, hostOnly, ok := j.cookieDomain(host, cookie.Domain)
+		if !ok {
+			continue
+		}
+		path := cookie.Path
+		if len(path) == 0 || path[0] != '/' {
+			path = defPath
+		}
+		key := domain + ";" + path + ";" + cookie.Name
+
+		e := &jarEntry{
+			Name:     cookie.Name,
+			Value:    cookie.Value,
+			Domain:   domain,
+			Path:     path,
+			HostOnly: hostOnly,
+			Secure:   cookie.Secure,
+			HttpOnly: cookie.HttpOnly,
+			SameSite: sameSiteString(cookie.SameSite),
+			Creation: now,
+		}
+		if cookie.MaxAge < 0 {
+			delete(j.entries, key)
+			continue
+		} else if cookie.MaxAge > 0 {
+			e.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+			e.Persistent = true
+		} else if !cookie.Expires.IsZero() {
+			if !cookie.Expires.After(now) {
+				delete(j.entries, key)
+				continue
+			}
+			e.Expires = cookie.Expires
+			e.Persistent = true
+		}
+		if old, ok := j.entries[key]; ok {
+			e.Creation = old.Creation
+			e.seqNum = old.seqNum
+		} else {
+			e.seqNum = j.nextSeqNum
+			j.nextSeqNum++
+		}
+		j.entries[key] = e
+	}
+}
+
+func (j *CookieJar) Cookies(u *url.URL) (cookies []*http.Cookie) {
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "ws" && u.Scheme != "wss" {
+		return nil
+	}
+	host, err := canonicalHost(u.Host)
+	if err != nil {
+		return nil
+	}
+	secure := u.Scheme == "https" || u.Scheme == "wss"
+	path := u.Path
+	if len(path) == 0 {
+		path = "/"
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := j.now()
+	selected := make([]*jarEntry, 0)
+	for key, e := range j.entries {
+		if e.Persistent && !e.Expires.After(now) {
+			delete(j.entries, key)
+			continue
+		}
+		if e.Secure && !secure {
+			continue
+		}
+		if !e.domainMatch(host) || !e.pathMatch(path) {
+			continue
+		}
+		selected = append(selected, e)
+	}
+	sort.Slice(selected, func(i, k int) bool {
+		if len(selected[i].Path) != len(selected[k].Path) {
+			return len(selected[i].Path) > len(selected[k].Path)
+		}
+		if !selected[i].Creation.Equal(selected[k].Creation) {
+			return selected[i].Creation.Before(selected[k].Creation)
+		}
+		return selected[i].seqNum < selected[k].seqNum
+	})
+	for _, e := range selected {
+		cookies = append(cookies, &http.Cookie{Name: e.Name, Value: e.Value})
+	}
+	return cookies
+}
+
+// All returns every non expired cookie with its attributes.
+func (j *CookieJar) All() []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	cookies := make([]*http.Cookie, 0, len(j.entries))
+	for _, e := range j.sortedEntries() {
+		cookie := &http.Cookie{
+			Name:     e.Name,
+			Value:    e.Value,
+			Domain:   e.Domain,
+			Path:     e.Path,
+			Secure:   e.Secure,
+			HttpOnly: e.HttpOnly,
+		}
+		if e.Persistent {
+			cookie.Expires = e.Expires
+		}
+		cookies = append(cookies, cookie)
+	}
+	return cookies
+}
+
+func (j *CookieJar) Save(w io.Writer, format CookieFormat) error {
+	j.mu.Lock()
+	entries := j.sortedEntries()
+	j.mu.Unlock()
+
+	switch format {
+	case CookieFormatJson:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case CookieFormatNetscape:
+		bw := bufio.NewWriter(w)
+		fmt.Fprintln(bw, "# Netscape HTTP Cookie File")
+		for _, e := range entries {
+			domain, includeSubdomains := e.Domain, "FALSE"
+			if !e.HostOnly {
+				domain, includeSubdomains = "."+e.Domain, "TRUE"
+			}
+			if e.HttpOnly {
+				domain = "#HttpOnly_" + domain
+			}
+			expires := int64(0)
+			if e.Persistent {
+				expires = e.Expires.Unix()
+			}
+			fmt.Fprintf(bw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+				domain, includeSubdomains, e.Path, strings.ToUpper(strconv.FormatBool(e.Secure)), expires, e.Name, e.Value)
+		}
+		return bw.Flush()
+	}
+	return fmt.Errorf("unknown cookie format %d", format)
+}
+
+// Load merges cookies stored by Save into the jar, expired cookies and
+// cookies without name or domain are skipped.
+func (j *CookieJar) Load(r io.Reader, format CookieFormat) error {
+	entries := make([]*jarEntry, 0)
+	switch format {
+	case CookieFormatJson:
+		if err := json.NewDecoder(r).Decode(&entries); err != nil {
+			return fmt.Errorf("cookie jar json decode error: %v", err)
+		}
+	case CookieFormatNetscape:
+		scanner := bufio.NewScanner(r)
+		for line := 1; scanner.Scan(); line++ {
+			// a trailing tab belongs to an empty value
+			text := strings.TrimRight(scanner.Text(), "\r\n")
+			httpOnly := strings.HasPrefix(text, "#HttpOnly_")
+			if httpOnly {
+				text = strings.TrimPrefix(text, "#HttpOnly_")
+			}
+			if len(strings.TrimSpace(text)) == 0 || strings.HasPrefix(text, "#") {
+				continue
+			}
+			fields := strings.Split(text, "\t")
+			if len(fields) != 7 {
+				return fmt.Errorf("cookie jar netscape line %d: expected 7 fields, got %d", line, len(fields))
+			}
+			expires, err := strconv.ParseInt(fields[4], 10, 64)
+			if err != nil {
+				return fmt.Errorf("cookie jar netscape line %d: invalid expiry: %v", line, err)
+			}
+			e := &jarEntry{
+				Name:     fields[5],
+				Value:    fields[6],
+				Domain:   strings.ToLower(strings.TrimPrefix(fields[0], ".")),
+				Path:     fields[2],
+				HostOnly: !strings.EqualFold(fields[1], "TRUE"),
+				Secure:   strings.EqualFold(fields[3], "TRUE"),
+				HttpOnly: httpOnly,
+			}
+			if expires > 0 {
+				e.Expires = time.Unix(expires, 0)
+				e.Persistent = true
+			}
+			entries = append(entries, e)
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown cookie format %d", format)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := j.now()
+	for _, e := range entries {
+		if len(e.Name) == 0 || len(e.Domain) == 0 || (e.Persistent && !e.Expires.After(now)) {
+			continue
+		}
+		if !strings.HasPrefix(e.Path, "/") {
+			e.Path = "/"
+		}
+		if e.Creation.IsZero() {
+			e.Creation = now
+		}
+		e.seqNum = j.nextSeqNum
+		j.nextSeqNum++
+		j.entries[e.Domain+";"+e.Path+";"+e.Name] = e
+	}
+	return nil
+}
+
+func (j *CookieJar) SaveFile(path string, format CookieFormat) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := j.Save(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (j *CookieJar) LoadFile(path string, format CookieFormat) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return j.Load(f, format)
+}
+
+func (j *CookieJar) sortedEntries() []*jarEntry {
+	now := j.now()
+	entries := make([]*jarEntry, 0, len(j.entries))
+	for _, e := range j.entries {
+		if e.Persistent && !e.Expires.After(now) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, k int) bool { return entries[i].seqNum < entries[k].seqNum })
+	return entries
+}
+
+// cookieDomain validates the cookie domain attribute against the request
+// host following RFC 6265 section 5.3.
+func (j *CookieJar) cookieDomain(host, domain string) (string, bool, bool) {
+	if len(domain) == 0 {
+		return host, true, true
+	}
+	if net.ParseIP(host) != nil {
+		return host, true, domain == host
+	}
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	if len(domain) == 0 || strings.HasSuffix(domain, ".") {
+		return "", false, false
+	}
+	if j.psList != nil {
+		if ps := j.psList.PublicSuffix(domain); len(ps) != 0 && !strings.HasSuffix(domain, "."+ps) {
+			if host == domain {
+				return host, true, true
+			}
+			return "", false, false
+		}
+	}
+	if host != domain && !strings.HasSuffix(host, "."+domain) {
+		return "", false, false
+	}
+	return domain, false, true
+}
+
+func (e *jarEntry) domainMatch(host string) bool {
+	if e.Domain == host {
+		return true
+	}
+	return !e.HostOnly && strings.HasSuffix(host, "."+e.Domain)
+}
+
+func (e *jarEntry) pathMatch(path string) bool {
+	if path == e.Path {
+		return true
+	}
+	if strings.HasPrefix(path, e.Path) {
+		if e.Path[len(e.Path)-1] == '/' || path[len(e.Path)] == '/' {
+			return true
+		}
+	}
+	return false
+}
+
+func canonicalHost(host string) (string, error) {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	if len(host) == 0 {
+		return "", fmt.Errorf("empty host")
+	}
+	return strings.ToLower(host), nil
+}
+
+func defaultCookiePath(path string) string {
+	if len(path) == 0 || path[0] != '/' {
+		return "/"
+	}
+	i := strings.LastIndex(path, "/")
+	if i == 0 {
+		return "/"
+	}
+	return path[:i]
+}
+
+func sameSiteString(sameSite http.SameSite) string {
+	switch sameSite {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package gorequests
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func (suite *RequestsSuite) TestCookieJarDomainMatching() {
+	// Test data
+	jar := NewCookieJar()
+	origin, _ := url.Parse("https://www.example.co.uk/account/login")
+
+	// Run test target
+	jar.SetCookies(origin, []*http.Cookie{
+		{Name: "host", Value: "1"},
+		{Name: "domain", Value: "2", Domain: ".example.co.uk", Path: "/"},
+		{Name: "suffix", Value: "3", Domain: "co.uk"},
+		{Name: "foreign", Value: "4", Domain: "example.com"},
+		{Name: "secure", Value: "5", Path: "/", Secure: true},
+	})
+	sameHost, _ := url.Parse("https://www.example.co.uk/account/profile")
+	subdomain, _ := url.Parse("http://api.example.co.uk/")
+
+	// Assertions
+	assert.ElementsMatch(suite.T(), []string{"host", "domain", "secure"}, cookieNames(jar.Cookies(sameHost)))
+	assert.ElementsMatch(suite.T(), []string{"domain"}, cookieNames(jar.Cookies(subdomain)))
+}
+
+func (suite *RequestsSuite) TestCookieJarPersistence() {
+	for _, format := range []CookieFormat{CookieFormatJson, CookieFormatNetscape} {
+		// Test data
+		jar := NewCookieJar()
+		origin, _ := url.Parse("https://example.com/")
+		jar.SetCookies(origin, []*http.Cookie{
+			{Name: "session", Value: "a", HttpOnly: true},
+			{Name: "remember", Value: "b", Domain: "example.com", Expires: time.Now().Add(time.Hour)},
+			{Name: "expired", Value: "c", Expires: time.Now().Add(-time.Hour)},
+			{Name: "empty", Value: ""},
+		})
+
+		// Run test target
+		buf := new(bytes.Buffer)
+		saveErr := jar.Save(buf, format)
+		loaded := NewCookieJar()
+		loadErr := loaded.Load(buf, format)
+		subdomain, _ := url.Parse("https://api.example.com/")
+
+		// Assertions
+		assert.NoError(suite.T(), saveErr, "should be saved without error")
+		assert.NoError(suite.T(), loadErr, "should be loaded without error")
+		assert.Equal(suite.T(), cookieNames(jar.Cookies(origin)), cookieNames(loaded.Cookies(origin)), "should restore cookies")
+		assert.Equal(suite.T(), []string{"remember"}, cookieNames(loaded.Cookies(subdomain)), "should restore domain cookies")
+		assert.True(suite.T(), loaded.All()[0].HttpOnly, "should restore attributes")
+	}
+}
+
+func (suite *RequestsSuite) TestCookieJarLoadInvalid() {
+	// Test data
+	jar := NewCookieJar()
+	data := `[{"name":"nopath","value":"1","domain":"example.com"},` +
+		`{"name":"","value":"2","domain":"example.com","path":"/"},` +
+		`{"name":"nodomain","value":"3","domain":"","path":"/"}]`
+	origin, _ := url.Parse("https://example.com/books")
+
+	// Run test target
+	err := jar.Load(strings.NewReader(data), CookieFormatJson)
+	var cookies []*http.Cookie
+	assert.NotPanics(suite.T(), func() { cookies = jar.Cookies(origin) })
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be loaded without error")
+	assert.Equal(suite.T(), []string{"nopath"}, cookieNames(cookies), "should skip invalid cookies")
+	assert.Equal(suite.T(), "/", jar.All()[0].Path, "should default path")
+}
+
+func cookieNames(cookies []*http.Cookie) []string {
+	names := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		names = append(names, cookie.Name)
+	}
+	return names
+}
@@ -0,0 +1,171 @@
+package gorequests
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Curl renders the request as an equivalent curl command line, optionally
+// with secrets hidden by the redaction.
+func (r *requestsInstance) Curl(redaction ...*Redaction) (string, error) {
+	// The body is rendered from a copy, Exec has to send it unread.
+	instance := r.Clone().(*requestsInstance)
+	streaming := false
+	switch body := r.body.(type) {
+	case nil:
+	case *bytes.Reader:
+		snapshot := *body
+		instance.body = &snapshot
+	case *strings.Reader:
+		snapshot := *body
+		instance.body = &snapshot
+	case *bytes.Buffer:
+		instance.body = bytes.NewReader(body.Bytes())
+	default:
+		streaming = r.form == nil && r.json == nil
+	}
+	if streaming {
+		instance.compressEncoding = ""
+	}
+	req, err := instance.buildRequest()
+	if err != nil {
+		return "", err
+	}
+	if streaming && len(r.compressEncoding) != 0 && len(req.Header.Get("Content-Encoding")) == 0 {
+		req.Header.Set("Content-Encoding", r.compressEncoding)
+	}
+	compressed := len(req.Header.Get("Accept-Encoding")) == 0 && r.acceptEncoding == nil
+	return curlCommand(req, firstRedaction(redaction), streaming, compressed)
+}
+
+// CurlCommand renders an http request as an equivalent curl command line.
+func CurlCommand(req *http.Request, redaction ...*Redaction) (string, error) {
+	return curlCommand(req, firstRedaction(redaction), false, false)
+}
+
+func curlCommand(req *http.Request, redaction *Redaction, streaming, compressed bool) (string, error) {
+	var body []byte
+	if !streaming {
+		var err error
+		if body, err = readRequestBody(req); err != nil {
+			return "", err
+		}
+	}
+	hasBody := streaming || len(body) > 0
+
+	command := "curl "
+	switch {
+	case req.Method == http.MethodHead:
+		command += "--head "
+	case req.Method == http.MethodGet && !hasBody, req.Method == http.MethodPost && hasBody:
+	case len(req.Method) == 0:
+	default:
+		command += "-X " + shellQuote(req.Method) + " "
+	}
+	lines := []string{command + shellQuote(redaction.Url(req.URL))}
+	if compressed {
+		lines = append(lines, "--compressed")
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	multipartBody := mediaType == "multipart/form-data" && len(params["boundary"]) != 0 && !streaming &&
+		len(req.Header.Get("Content-Encoding")) == 0
+
+	header := redaction.Header(req.Header)
+	if len(req.Host) != 0 && req.Host != req.URL.Host {
+		header.Set("Host", req.Host)
+	}
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch {
+		case name == "Cookie", name == "Content-Length":
+			continue
+		case name == "Content-Type" && multipartBody:
+			continue
+		}
+		for _, value := range header[name] {
+			lines = append(lines, "-H "+shellQuote(name+": "+value))
+		}
+	}
+	if cookies := header.Values("Cookie"); len(cookies) != 0 {
+		lines = append(lines, "-b "+shellQuote(strings.Join(cookies, "; ")))
+	}
+
+	switch {
+	case streaming:
+		lines = append(lines, "--data-binary @-")
+	case multipartBody:
+		parts, err := curlMultipart(body, params["boundary"], redaction)
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, parts...)
+	case len(body) > 0:
+		body = redaction.Body(contentType, body)
+		if utf8.Valid(body) && bytes.IndexByte(body, 0) < 0 {
+			lines = append(lines, "--data-raw "+shellQuote(string(body)))
+		} else {
+			lines = append(lines, "--data-binary @-")
+		}
+	}
+	return strings.Join(lines, " \\\n  "), nil
+}
+
+func curlMultipart(body []byte, boundary string, redaction *Redaction) ([]string, error) {
+	lines := make([]string, 0)
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return lines, nil
+		} else if err != nil {
+			return nil, err
+		}
+		name := part.FormName()
+		if len(part.FileName()) != 0 {
+			field := name + "=@" + part.FileName()
+			if contentType := part.Header.Get("Content-Type"); len(contentType) != 0 {
+				field += ";type=" + contentType
+			}
+			lines = append(lines, "-F "+shellQuote(field))
+			continue
+		}
+		value, err := ioutil.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		if redaction != nil && redaction.query[strings.ToLower(name)] {
+			value = []byte(redaction.replacement)
+		}
+		lines = append(lines, "--form-string "+shellQuote(name+"="+string(value)))
+	}
+}
+
+func firstRedaction(redaction []*Redaction) *Redaction {
+	if len(redaction) > 0 {
+		return redaction[0]
+	}
+	return nil
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
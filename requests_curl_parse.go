@@ -0,0 +1,479 @@
+package gorequests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ParseCurlOptions configure ParseCurl.
+type ParseCurlOptions struct {
+	// Files is used to read @file arguments of -d, --data-urlencode and -F,
+	// absolute paths are looked up from its root, e.g. os.DirFS("/"). Commands
+	// referencing files fail when it is nil, so pasted commands can not send
+	// local files.
+	Files fs.FS
+}
+
+// ParseCurl builds a request from a curl command line, e.g. one copied from
+// browser devtools.
+func ParseCurl(command string, options ...*ParseCurlOptions) (RequestsInstance, error) {
+	args, err := shellSplit(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || (args[0] != "curl" && args[0] != "curl.exe") {
+		return nil, fmt.Errorf("curl command expected")
+	}
+
+	p := &curlParser{header: http.Header{}}
+	if len(options) > 0 && options[0] != nil {
+		p.files = options[0].Files
+	}
+	if err := p.parse(args[1:]); err != nil {
+		return nil, err
+	}
+	return p.build()
+}
+
+type curlParser struct {
+	method    string
+	url       string
+	header    http.Header
+	cookies   []*http.Cookie
+	data      []string
+	dataSet   bool
+	form      *multipart.Writer
+	formBody  *bytes.Buffer
+	get       bool
+	head      bool
+	user      string
+	userIsSet bool
+	files     fs.FS
+}
+
+var curlNoArgOptions = map[string]bool{
+	"--compressed": true, "-s": true, "--silent": true, "-S": true, "--show-error": true,
+	"-L": true, "--location": true, "-v": true, "--verbose": true, "-i": true, "--include": true,
+	"-g": true, "--globoff": true, "--http1.1": true, "--http2": true, "-N": true, "--no-buffer": true,
+	"-f": true, "--fail": true, "-#": true, "--progress-bar": true,
+	"-G": true, "--get": true, "-I": true, "--head": true,
+}
+
+var curlArgOptions = map[string]bool{
+	"-X": true, "--request": true, "-H": true, "--header": true, "-d": true, "--data": true,
+	"--data-ascii": true, "--data-raw": true, "--data-binary": true, "--data-urlencode": true,
+	"-F": true, "--form": true, "--form-string": true, "-b": true, "--cookie": true,
+	"-u": true, "--user": true, "-A": true, "--user-agent": true, "-e": true, "--referer": true,
+	"--url": true, "-o": true, "--output": true, "-m": true, "--max-time": true,
+	"--connect-timeout": true, "--retry": true,
+}
+
+func (p *curlParser) parse(args []string) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			p.url = arg
+			continue
+		}
+
+		option, value, hasValue := arg, "", false
+		if !strings.HasPrefix(arg, "--") && len(arg) > 2 {
+			option = arg[:2]
+			if curlArgOptions[option] {
+				value, hasValue = arg[2:], true
+			} else {
+				// Combined short options like -sSL
+				for _, c := range arg[1:] {
+					if short := "-" + string(c); !curlNoArgOptions[short] {
+						return fmt.Errorf("unsupported curl option %q", arg)
+					} else if err := p.option(short, ""); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+		}
+		if curlArgOptions[option] && !hasValue {
+			if i+1 >= len(args) {
+				return fmt.Errorf("curl option %s requires a value", option)
+			}
+			i++
+			value = args[i]
+		} else if !curlArgOptions[option] && !curlNoArgOptions[option] {
+			return fmt.Errorf("unsupported curl option %q", option)
+		}
+		if err := p.option(option, value); err != nil {
+			return err
+		}
+	}
+	if len(p.url) == 0 {
+		return fmt.Errorf("curl command without url")
+	}
+	return nil
+}
+
+func (p *curlParser) option(option, value string) error {
+	switch option {
+	case "-X", "--request":
+		p.method = value
+	case "-H", "--header":
+		name, headerValue, ok := strings.Cut(value, ":")
+		if !ok {
+			// "Name;" sends an empty header, "Name" removes it.
+			if name = strings.TrimSuffix(value, ";"); name != value {
+				p.header.Add(name, "")
+			}
+			return nil
+		}
+		name = strings.TrimSpace(name)
+		if strings.EqualFold(name, "Cookie") {
+			p.cookies = append(p.cookies, parseCurlCookies(headerValue)...)
+			return nil
+		}
+		p.header.Add(name, strings.TrimSpace(headerValue))
+	case "-d", "--data", "--data-ascii":
+		data, err := p.readData(value, true)
+		if err != nil {
+			return err
+		}
+		p.addData(data)
+	case "--data-raw":
+		p.addData(value)
+	case "--data-binary":
+		data, err := p.readData(value, false)
+		if err != nil {
+			return err
+		}
+		p.addData(data)
+	case "--data-urlencode":
+		data, err := p.urlencode(value)
+		if err != nil {
+			return err
+		}
+		p.addData(data)
+	case "-F", "--form", "--form-string":
+		return p.addFormField(value, option == "--form-string")
+	case "-b", "--cookie":
+		if !strings.Contains(value, "=") {
+			return fmt.Errorf("curl cookie files are not supported: %s", value)
+		}
+		p.cookies = append(p.cookies, parseCurlCookies(value)...)
+	case "-u", "--user":
+		p.user, p.userIsSet = value, true
+	case "-A", "--user-agent":
+		p.header.Set("User-Agent", value)
+	case "-e", "--referer":
+		p.header.Set("Referer", value)
+	case "--url":
+		p.url = value
+	case "-G", "--get":
+		p.get = true
+	case "-I", "--head":
+		p.head = true
+	}
+	return nil
+}
+
+func (p *curlParser) addData(data string) {
+	p.data = append(p.data, data)
+	p.dataSet = true
+}
+
+func (p *curlParser) addFormField(value string, literal bool) error {
+	if p.form == nil {
+		p.formBody = new(bytes.Buffer)
+		p.form = multipart.NewWriter(p.formBody)
+	}
+	name, content, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("invalid curl form field %q", value)
+	}
+	if literal || (!strings.HasPrefix(content, "@") && !strings.HasPrefix(content, "<")) {
+		return p.form.WriteField(name, content)
+	}
+
+	// name=@file;type=text/plain;filename=name.txt or name=<file
+	params := strings.Split(content[1:], ";")
+	path, contentType, filename := params[0], "", filepath.Base(params[0])
+	for _, param := range params[1:] {
+		key, paramValue, _ := strings.Cut(param, "=")
+		switch strings.TrimSpace(key) {
+		case "type":
+			contentType = paramValue
+		case "filename":
+			filename = strings.Trim(paramValue, `"`)
+		}
+	}
+	data, err := p.readFile(path)
+	if err != nil {
+		return fmt.Errorf("curl form field %s: %v", name, err)
+	}
+	if content[0] == '<' {
+		return p.form.WriteField(name, string(data))
+	}
+	if len(contentType) == 0 {
+		contentType = "application/octet-stream"
+	}
+	partHeader := textproto.MIMEHeader{}
+	partHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%s; filename=%s`, strconv.Quote(name), strconv.Quote(filename)))
+	partHeader.Set("Content-Type", contentType)
+	part, err := p.form.CreatePart(partHeader)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(data)
+	return err
+}
+
+func (p *curlParser) build() (RequestsInstance, error) {
+	method := p.method
+	rawUrl := p.url
+	if !strings.Contains(rawUrl, "://") {
+		rawUrl = "http://" + rawUrl
+	}
+	r := Requests()
+
+	switch {
+	case p.get && p.dataSet:
+		u, err := url.Parse(rawUrl)
+		if err != nil {
+			return nil, err
+		}
+		query := strings.Join(p.data, "&")
+		if len(u.RawQuery) != 0 {
+			query = u.RawQuery + "&" + query
+		}
+		u.RawQuery = query
+		rawUrl = u.String()
+	case p.form != nil:
+		if err := p.form.Close(); err != nil {
+			return nil, err
+		}
+		r.Data(p.formBody.Bytes(), p.form.FormDataContentType())
+		p.header.Del("Content-Type")
+	case p.dataSet:
+		if len(p.header.Get("Content-Type")) != 0 {
+			r.Data([]byte(strings.Join(p.data, "&")))
+		} else {
+			r.Data([]byte(strings.Join(p.data, "&")), "application/x-www-form-urlencoded")
+		}
+	}
+
+	if len(method) == 0 {
+		switch {
+		case p.head:
+			method = http.MethodHead
+		case !p.get && (p.dataSet || p.form != nil):
+			method = http.MethodPost
+		default:
+			method = http.MethodGet
+		}
+	}
+	r.Method(method).Url("%s", rawUrl)
+
+	for name, values := range p.header {
+		for _, value := range values {
+			r.Header(name, value)
+		}
+	}
+	if len(p.cookies) != 0 {
+		r.Cookies(p.cookies...)
+	}
+	if p.userIsSet {
+		r.Header("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(p.user)))
+	}
+	return r, nil
+}
+
+func parseCurlCookies(value string) []*http.Cookie {
+	cookies := make([]*http.Cookie, 0)
+	for _, pair := range strings.Split(value, ";") {
+		name, cookieValue, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || len(name) == 0 {
+			continue
+		}
+		cookies = append(cookies, &http.Cookie{Name: name, Value: cookieValue})
+	}
+	return cookies
+}
+
+// readFile reads a file referenced by the command from the allowed files.
+func (p *curlParser) readFile(name string) ([]byte, error) {
+	if p.files == nil {
+		return nil, fmt.Errorf("reading %s is not allowed without ParseCurlOptions.Files", name)
+	}
+	return fs.ReadFile(p.files, strings.TrimPrefix(filepath.ToSlash(name), "/"))
+}
+
+// readData reads @file arguments, -d strips new lines from files like curl
+// does.
+func (p *curlParser) readData(value string, stripNewLines bool) (string, error) {
+	if !strings.HasPrefix(value, "@") {
+		return value, nil
+	}
+	data, err := p.readFile(value[1:])
+	if err != nil {
+		return "", fmt.Errorf("curl data file: %v", err)
+	}
+	if stripNewLines {
+		data = bytes.ReplaceAll(bytes.ReplaceAll(data, []byte("\r"), nil), []byte("\n"), nil)
+	}
+	return string(data), nil
+}
+
+// urlencode implements the content, =content, name=content, @file and
+// name@file forms of --data-urlencode.
+func (p *curlParser) urlencode(value string) (string, error) {
+	eq := strings.Index(value, "=")
+	at := strings.Index(value, "@")
+	switch {
+	case eq >= 0 && (at < 0 || eq < at):
+		name, content := value[:eq], value[eq+1:]
+		if len(name) == 0 {
+			return url.QueryEscape(content), nil
+		}
+		return name + "=" + url.QueryEscape(content), nil
+	case at >= 0:
+		name := value[:at]
+		data, err := p.readFile(value[at+1:])
+		if err != nil {
+			return "", fmt.Errorf("curl data file: %v", err)
+		}
+		if len(name) == 0 {
+			return url.QueryEscape(string(data)), nil
+		}
+		return name + "=" + url.QueryEscape(string(data)), nil
+	}
+	return url.QueryEscape(value), nil
+}
+
+// shellSplit splits a POSIX shell command line supporting quotes, $'...'
+// strings and line continuations.
+func shellSplit(command string) ([]string, error) {
+	args := make([]string, 0)
+	var current strings.Builder
+	inArg := false
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '\\' && i+1 < len(runes) && (runes[i+1] == '\n' || runes[i+1] == '\r'):
+			i++
+			if runes[i] == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+				i++
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case c == '\\':
+			inArg = true
+			if i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+			}
+		case c == '\'':
+			inArg = true
+			end := indexRune(runes, '\'', i+1)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			i = end
+		case c == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			inArg = true
+			n, err := ansiCString(runes[i+2:], &current)
+			if err != nil {
+				return nil, err
+			}
+			i += 2 + n
+		case c == '"':
+			inArg = true
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '"' {
+					closed = true
+					break
+				}
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+		default:
+			inArg = true
+			current.WriteRune(c)
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// ansiCString decodes a $'...' string body and returns the number of runes
+// consumed including the closing quote.
+func ansiCString(runes []rune, out *strings.Builder) (int, error) {
+	escapes := map[rune]string{'n': "\n", 't': "\t", 'r': "\r", '\\': "\\", '\'': "'", '"': "\"", 'a': "\a", 'b': "\b", 'e': "\x1b", 'f': "\f", 'v': "\v"}
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; {
+		case c == '\'':
+			return i + 1, nil
+		case c == '\\' && i+1 < len(runes):
+			i++
+			if escaped, ok := escapes[runes[i]]; ok {
+				out.WriteString(escaped)
+				continue
+			}
+			if runes[i] == 'x' || runes[i] == 'u' || runes[i] == 'U' {
+				size := map[rune]int{'x': 2, 'u': 4, 'U': 8}[runes[i]]
+				end := i + 1
+				for end < len(runes) && end < i+1+size && strings.ContainsRune("0123456789abcdefABCDEF", runes[end]) {
+					end++
+				}
+				code, err := strconv.ParseUint(string(runes[i+1:end]), 16, 32)
+				if err != nil {
+					return 0, fmt.Errorf("invalid escape in $'' string")
+				}
+				if runes[i] == 'x' {
+					out.WriteByte(byte(code))
+				} else {
+					out.WriteRune(rune(code))
+				}
+				i = end - 1
+				continue
+			}
+			out.WriteRune('\\')
+			out.WriteRune(runes[i])
+		default:
+			out.WriteRune(c)
+		}
+	}
+	return 0, fmt.Errorf("unterminated $'' string")
+}
+
+func indexRune(runes []rune, r rune, from int) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
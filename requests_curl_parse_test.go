@@ -0,0 +1,201 @@
+package gorequests
+
+import (
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+func (suite *RequestsSuite) TestParseCurl() {
+	// Test data
+	command := `curl 'http://localhost/books?page=1' \
+  -X 'PATCH' \
+  -H 'accept: application/json' \
+  -H "X-Request-Id: \"42\"" \
+  -b 'session=abc; theme=dark' \
+  -u john:secret \
+  --data-raw $'{"title":"it\'s"}' \
+  --compressed`
+
+	// Mocking http calls
+	var actual *http.Request
+	var actualBody []byte
+	httpmock.RegisterResponder(http.MethodPatch, "http://localhost/books?page=1", func(request *http.Request) (*http.Response, error) {
+		actual = request
+		actualBody, _ = readRequestBody(request)
+		return httpmock.NewStringResponse(http.StatusOK, ""), nil
+	})
+
+	// Run test target
+	target, err := ParseCurl(command)
+	if assert.NoError(suite.T(), err, "should be parsed without error") {
+		err = target.Exec()
+	}
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	if assert.NotNil(suite.T(), actual) {
+		assert.Equal(suite.T(), "application/json", actual.Header.Get("Accept"))
+		assert.Equal(suite.T(), `"42"`, actual.Header.Get("X-Request-Id"))
+		assert.Equal(suite.T(), "application/x-www-form-urlencoded", actual.Header.Get("Content-Type"))
+		user, password, _ := actual.BasicAuth()
+		assert.Equal(suite.T(), "john:secret", user+":"+password)
+		cookie, _ := actual.Cookie("theme")
+		assert.Equal(suite.T(), "dark", cookie.Value)
+		assert.Equal(suite.T(), `{"title":"it's"}`, string(actualBody))
+	}
+}
+
+func (suite *RequestsSuite) TestParseCurlData() {
+	tests := []struct {
+		name           string
+		command        string
+		expectedMethod string
+		expectedUrl    string
+		expectedBody   string
+	}{
+		{
+			name:           "data",
+			command:        "curl -d a=1 --data b=2 localhost/books",
+			expectedMethod: http.MethodPost,
+			expectedUrl:    "http://localhost/books",
+			expectedBody:   "a=1&b=2",
+		},
+		{
+			name:           "urlencode",
+			command:        "curl --data-urlencode 'q=a b&c' --data-urlencode =x/y http://localhost/search",
+			expectedMethod: http.MethodPost,
+			expectedUrl:    "http://localhost/search",
+			expectedBody:   "q=a+b%26c&x%2Fy",
+		},
+		{
+			name:           "get",
+			command:        "curl -sSL -G -d q=go http://localhost/search?page=2",
+			expectedMethod: http.MethodGet,
+			expectedUrl:    "http://localhost/search?page=2&q=go",
+			expectedBody:   "",
+		},
+	}
+
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			// Mocking http calls
+			var actualBody []byte
+			httpmock.RegisterResponder(test.expectedMethod, test.expectedUrl, func(request *http.Request) (*http.Response, error) {
+				actualBody, _ = readRequestBody(request)
+				return httpmock.NewStringResponse(http.StatusOK, ""), nil
+			})
+
+			// Run test target
+			target, err := ParseCurl(test.command)
+			if assert.NoError(suite.T(), err, "should be parsed without error") {
+				err = target.Exec()
+			}
+
+			// Assertions
+			assert.NoError(suite.T(), err, "should be run without error")
+			assert.Equal(suite.T(), test.expectedBody, string(actualBody))
+		})
+	}
+}
+
+func (suite *RequestsSuite) TestParseCurlForm() {
+	// Test data
+	dir := suite.T().TempDir()
+	_ = ioutil.WriteFile(filepath.Join(dir, "cover.png"), []byte("PNG"), 0600)
+	_ = ioutil.WriteFile(filepath.Join(dir, "note.txt"), []byte("a&b"), 0600)
+
+	// Mocking http calls
+	fields := make(map[string]string)
+	var fileName, fileType string
+	httpmock.RegisterResponder(http.MethodPost, "http://localhost/books", func(request *http.Request) (*http.Response, error) {
+		_, params, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+		reader := multipart.NewReader(request.Body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			value, _ := ioutil.ReadAll(part)
+			fields[part.FormName()] = string(value)
+			if len(part.FileName()) != 0 {
+				fileName, fileType = part.FileName(), part.Header.Get("Content-Type")
+			}
+		}
+		return httpmock.NewStringResponse(http.StatusOK, ""), nil
+	})
+
+	// Run test target
+	target, err := ParseCurl("curl http://localhost/books -F 'title=A book' --form-string 'note=@home' -F 'cover=@cover.png;type=image/png'",
+		&ParseCurlOptions{Files: os.DirFS(dir)})
+	if assert.NoError(suite.T(), err, "should be parsed without error") {
+		err = target.Exec()
+	}
+	absolute, absoluteErr := ParseCurl("curl http://localhost/notes --data-urlencode note@"+filepath.Join(dir, "note.txt"),
+		&ParseCurlOptions{Files: os.DirFS("/")})
+	var absoluteBody []byte
+	if assert.NoError(suite.T(), absoluteErr, "should read absolute path from root") {
+		absoluteBody = absolute.(*requestsInstance).data
+	}
+	_, deniedErr := ParseCurl("curl http://localhost/books -F 'cover=@" + filepath.Join(dir, "cover.png") + "'")
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), map[string]string{"title": "A book", "note": "@home", "cover": "PNG"}, fields)
+	assert.Equal(suite.T(), "cover.png", fileName)
+	assert.Equal(suite.T(), "image/png", fileType)
+	assert.Equal(suite.T(), "note=a%26b", string(absoluteBody))
+	assert.Error(suite.T(), deniedErr, "should not read files without options")
+}
+
+func (suite *RequestsSuite) TestParseCurlRoundTrip() {
+	// Test data
+	command := "curl -X PUT http://localhost/books/1 \\\n  --compressed \\\n" +
+		"  -H 'Content-Type: application/json' \\\n" +
+		"  -H 'X-Request-Id: it'\\''s' \\\n" +
+		"  -b session=abc \\\n" +
+		"  --data-raw '{\"title\":\"A book\"}'"
+
+	// Run test target
+	target, err := ParseCurl(command)
+	var actual string
+	if assert.NoError(suite.T(), err, "should be parsed without error") {
+		actual, err = target.Curl()
+	}
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be rendered without error")
+	assert.Equal(suite.T(), command, actual)
+}
+
+func (suite *RequestsSuite) TestParseCurlErrors() {
+	tests := []struct {
+		name    string
+		command string
+	}{
+		{name: "not curl", command: "wget http://localhost"},
+		{name: "unterminated quote", command: "curl 'http://localhost"},
+		{name: "unsupported option", command: "curl --upload-file x http://localhost"},
+		{name: "missing value", command: "curl http://localhost -H"},
+		{name: "missing url", command: "curl -X GET"},
+		{name: "missing file", command: "curl -d @" + filepath.Join(os.TempDir(), "missing-gorequests") + " http://localhost"},
+		{name: "data file not allowed", command: "curl -d @/etc/hostname http://localhost"},
+		{name: "urlencode file not allowed", command: "curl --data-urlencode name@/etc/hostname http://localhost"},
+		{name: "form file not allowed", command: "curl -F 'file=</etc/hostname' http://localhost"},
+	}
+
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			// Run test target
+			_, err := ParseCurl(test.command)
+
+			// Assertions
+			assert.Error(suite.T(), err, "should be failed")
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package gorequests
+
+import (
+	"bytes"
+	"context"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+func (suite *RequestsSuite) TestCurl() {
+	tests := []struct {
+		name     string
+		target   RequestsInstance
+		expected string
+	}{
+		{
+			name:     "get",
+			target:   Get("http://localhost/books?page=1"),
+			expected: "curl 'http://localhost/books?page=1' \\\n  --compressed",
+		},
+		{
+			name: "json",
+			target: Put("http://localhost/books/%d", 1).
+				Header("X-Request-Id", "it's").
+				Cookies(&http.Cookie{Name: "session", Value: "abc"}).
+				Json(map[string]string{"title": "A book"}),
+			expected: "curl -X PUT http://localhost/books/1 \\\n  --compressed \\\n" +
+				"  -H 'Content-Type: application/json' \\\n" +
+				"  -H 'X-Request-Id: it'\\''s' \\\n" +
+				"  -b session=abc \\\n" +
+				"  --data-raw '{\"title\":\"A book\"}'",
+		},
+		{
+			name:     "form",
+			target:   Post("http://localhost/login").AcceptEncoding().Form(url.Values{"user": {"john"}}),
+			expected: "curl http://localhost/login \\\n  -H 'Content-Type: application/x-www-form-urlencoded' \\\n  --data-raw user=john",
+		},
+		{
+			name:     "head",
+			target:   Head("http://localhost/books").AcceptEncoding(),
+			expected: "curl --head http://localhost/books",
+		},
+	}
+
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			// Run test target
+			actual, err := test.target.Curl()
+
+			// Assertions
+			assert.NoError(suite.T(), err, "should be run without error")
+			assert.Equal(suite.T(), test.expected, actual)
+		})
+	}
+}
+
+func (suite *RequestsSuite) TestCurlKeepsBody() {
+	// Test data
+	reqUrl := "http://localhost/upload"
+	bodies := make([]string, 0)
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodPost, reqUrl, func(request *http.Request) (*http.Response, error) {
+		decoded, err := decodeResponseBody(&http.Response{Header: request.Header, Body: request.Body})
+		if err != nil {
+			return nil, err
+		}
+		body, _ := ioutil.ReadAll(decoded)
+		bodies = append(bodies, string(body))
+		return httpmock.NewStringResponse(http.StatusOK, ""), nil
+	})
+
+	// Run test target
+	stream := Post(reqUrl).Body(ioutil.NopCloser(strings.NewReader("0123456789abcdefghijklmnop")), "text/plain").CompressBody(EncodingGzip, 10)
+	streamCurl, streamCurlErr := stream.Curl()
+	streamErr := stream.Exec()
+	buffer := Post(reqUrl).Body(bytes.NewBufferString("0123456789abcdefghijklmnop"), "text/plain").CompressBody(EncodingGzip, 10)
+	_, bufferCurlErr := buffer.Curl()
+	bufferErr := buffer.Exec()
+
+	// Assertions
+	assert.NoError(suite.T(), streamCurlErr)
+	assert.Equal(suite.T(), "curl http://localhost/upload \\\n  --compressed \\\n"+
+		"  -H 'Content-Encoding: gzip' \\\n  -H 'Content-Type: text/plain' \\\n  --data-binary @-", streamCurl)
+	assert.NoError(suite.T(), streamErr)
+	assert.NoError(suite.T(), bufferCurlErr)
+	assert.NoError(suite.T(), bufferErr)
+	assert.Equal(suite.T(), []string{"0123456789abcdefghijklmnop", "0123456789abcdefghijklmnop"}, bodies, "should send whole body after Curl")
+}
+
+func (suite *RequestsSuite) TestCurlRedacted() {
+	// Test data
+	redaction := DefaultRedaction().Query("token").JsonFields("password")
+	target := Post("http://localhost/login?token=secret").
+		AcceptEncoding().
+		Header("Authorization", "Bearer secret").
+		Json(map[string]string{"password": "secret"})
+
+	// Run test target
+	actual, err := target.Curl(redaction)
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.NotContains(suite.T(), actual, "secret", "should hide secrets")
+	assert.Contains(suite.T(), actual, "-H 'Authorization: REDACTED'")
+	assert.Contains(suite.T(), actual, `--data-raw '{"password":"REDACTED"}'`)
+}
+
+func (suite *RequestsSuite) TestCurlMultipart() {
+	// Test data
+	buf := new(bytes.Buffer)
+	w := multipart.NewWriter(buf)
+	_ = w.WriteField("title", "A book")
+	fw, _ := w.CreateFormFile("cover", "cover.png")
+	_, _ = fw.Write([]byte("PNG"))
+	_ = w.Close()
+	target := Post("http://localhost/books").AcceptEncoding().Data(buf.Bytes(), w.FormDataContentType())
+
+	// Run test target
+	actual, err := target.Curl()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), "curl http://localhost/books \\\n"+
+		"  --form-string 'title=A book' \\\n"+
+		"  -F 'cover=@cover.png;type=application/octet-stream'", actual)
+}
+
+func (suite *RequestsSuite) TestLoggingCurl() {
+	// Test data
+	method := http.MethodPost
+	reqUrl := "http://localhost/books"
+	entries := make([]*LogEntry, 0)
+	logging := Logging(LoggerFunc(func(ctx context.Context, entry *LogEntry) { entries = append(entries, entry) })).Curl(true)
+
+	// Mocking http calls
+	var actualBody []byte
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		actualBody, _ = readRequestBody(request)
+		return httpmock.NewStringResponse(http.StatusOK, ""), nil
+	})
+
+	// Run test target
+	err := Post(reqUrl).Use(logging).Data([]byte("title=A"), "text/plain").Exec()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), "title=A", string(actualBody), "should keep body")
+	if assert.Len(suite.T(), entries, 1) {
+		assert.True(suite.T(), strings.HasPrefix(entries[0].Curl, "curl http://localhost/books"), entries[0].Curl)
+		assert.Contains(suite.T(), entries[0].Curl, "--data-raw title=A")
+	}
+}
@@ -0,0 +1,251 @@
+package gorequests
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+	EncodingBrotli  = "br"
+	EncodingZstd    = "zstd"
+)
+
+var ErrResponseTooLarge = errors.New("response body exceeds size limit")
+
+// DefaultResponseLimit caps decoded response bodies read by Exec unless
+// ResponseLimit is set, protecting against decompression bombs.
+var DefaultResponseLimit int64 = 64 << 20
+
+var defaultAcceptEncoding = []string{EncodingGzip, EncodingDeflate, EncodingBrotli, EncodingZstd}
+
+// decodeResponseBody undoes every content coding listed in Content-Encoding.
+// Bodies with a coding that was not requested in Accept-Encoding, like the
+// "Content-Encoding: utf-8" some servers send, are returned as is.
+func decodeResponseBody(res *http.Response) (io.ReadCloser, error) {
+	encodings := make([]string, 0)
+	for _, value := range res.Header.Values("Content-Encoding") {
+		for _, encoding := range strings.Split(value, ",") {
+			encoding = strings.ToLower(strings.TrimSpace(encoding))
+			if len(encoding) != 0 && encoding != "identity" {
+				encodings = append(encodings, encoding)
+			}
+		}
+	}
+	if len(encodings) == 0 || res.Body == nil {
+		return res.Body, nil
+	}
+	for _, encoding := range encodings {
+		if !requestedEncoding(res.Request, encoding) {
+			return res.Body, nil
+		}
+	}
+	buffered := bufio.NewReader(res.Body)
+	if _, err := buffered.Peek(1); err == io.EOF {
+		return res.Body, nil
+	}
+
+	var body io.Reader = buffered
+	closers := make([]io.Closer, 0, len(encodings))
+	for i := len(encodings) - 1; i >= 0; i-- {
+		decoder, err := newDecoder(encodings[i], body)
+		if err != nil {
+			for _, closer := range closers {
+				closer.Close()
+			}
+			return nil, fmt.Errorf("response %s decode error: %v", encodings[i], err)
+		}
+		closers = append(closers, decoder)
+		body = decoder
+	}
+	return &decodedBody{Reader: body, closers: append(closers, res.Body)}, nil
+}
+
+// requestedEncoding reports whether the encoding is supported and was
+// accepted by the request, any supported encoding is accepted without
+// request.
+func requestedEncoding(req *http.Request, encoding string) bool {
+	switch encoding {
+	case EncodingGzip, "x-gzip", EncodingDeflate, EncodingBrotli, EncodingZstd:
+	default:
+		return false
+	}
+	if req == nil {
+		return true
+	}
+	for _, value := range strings.Split(req.Header.Get("Accept-Encoding"), ",") {
+		name := strings.ToLower(strings.TrimSpace(strings.Split(value, ";")[0]))
+		if name == encoding || name == "*" || (name == EncodingGzip && encoding == "x-gzip") {
+			return true
+		}
+	}
+	return false
+}
+
+func newDecoder(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch encoding {
+	case EncodingGzip, "x-gzip":
+		return gzip.NewReader(r)
+	case EncodingDeflate:
+		// Some servers send raw deflate instead of the zlib format.
+		buffered := bufio.NewReader(r)
+		header, err := buffered.Peek(2)
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(buffered)
+		}
+		return flate.NewReader(buffered), nil
+	case EncodingBrotli:
+		return ioutil.NopCloser(brotli.NewReader(r)), nil
+	case EncodingZstd:
+		decoder, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	}
+	return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+}
+
+type decodedBody struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (b *decodedBody) Close() (err error) {
+	for _, closer := range b.closers {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+func newEncoder(encoding string, w io.Writer) (io.WriteCloser, error) {
+	switch encoding {
+	case EncodingGzip:
+		return gzip.NewWriter(w), nil
+	case EncodingDeflate:
+		return zlib.NewWriter(w), nil
+	case EncodingBrotli:
+		return brotli.NewWriter(w), nil
+	case EncodingZstd:
+		return zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	}
+	return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+}
+
+// compressRequestBody compresses bodies of at least minSize bytes, in memory
+// bodies are compressed at once and streams while they are sent.
+func compressRequestBody(encoding string, minSize int, body io.Reader) (io.Reader, bool, error) {
+	w, err := newEncoder(encoding, ioutil.Discard)
+	if err != nil {
+		return nil, false, err
+	}
+	w.Close()
+	prefix := make([]byte, minSize)
+	n, err := io.ReadFull(body, prefix)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return bytes.NewReader(prefix[:n]), false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	rest := io.MultiReader(bytes.NewReader(prefix), body)
+
+	switch body.(type) {
+	case *bytes.Reader, *strings.Reader, *bytes.Buffer:
+		buf := new(bytes.Buffer)
+		w, _ := newEncoder(encoding, buf)
+		if _, err := io.Copy(w, rest); err != nil {
+			return nil, false, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, false, err
+		}
+		return bytes.NewReader(buf.Bytes()), true, nil
+	}
+	closer, _ := body.(io.Closer)
+	return &compressingReader{encoding: encoding, body: rest, closer: closer}, true, nil
+}
+
+// compressingReader compresses the body in a goroutine started on the first
+// read, so nothing leaks when the request is never sent.
+type compressingReader struct {
+	encoding string
+	body     io.Reader
+	closer   io.Closer
+	once     sync.Once
+	pr       *io.PipeReader
+}
+
+func (r *compressingReader) start() {
+	pr, pw := io.Pipe()
+	r.pr = pr
+	go func() {
+		if r.closer != nil {
+			defer r.closer.Close()
+		}
+		w, err := newEncoder(r.encoding, pw)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(w, r.body); err != nil {
+			w.Close()
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(w.Close())
+	}()
+}
+
+func (r *compressingReader) Read(p []byte) (int, error) {
+	r.once.Do(r.start)
+	if r.pr == nil {
+		return 0, io.ErrClosedPipe
+	}
+	return r.pr.Read(p)
+}
+
+func (r *compressingReader) Close() error {
+	started := true
+	r.once.Do(func() { started = false })
+	if !started {
+		if r.closer != nil {
+			return r.closer.Close()
+		}
+		return nil
+	}
+	return r.pr.Close()
+}
+
+// readLimited reads the whole body failing with ErrResponseTooLarge when it
+// is longer than limit, a negative limit means unlimited.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit < 0 {
+		return ioutil.ReadAll(r)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, ErrResponseTooLarge
+	}
+	return body, nil
+}
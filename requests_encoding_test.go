@@ -0,0 +1,240 @@
+package gorequests
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"github.com/andybalholm/brotli"
+	"github.com/jarcoal/httpmock"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+)
+
+func (suite *RequestsSuite) TestResponseDecompression() {
+	tests := []struct {
+		name     string
+		encoding string
+		writer   func(w io.Writer) io.WriteCloser
+	}{
+		{name: "gzip", encoding: EncodingGzip, writer: func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }},
+		{name: "deflate", encoding: EncodingDeflate, writer: func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }},
+		{name: "raw deflate", encoding: EncodingDeflate, writer: func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		}},
+		{name: "br", encoding: EncodingBrotli, writer: func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) }},
+		{name: "zstd", encoding: EncodingZstd, writer: func(w io.Writer) io.WriteCloser {
+			zw, _ := zstd.NewWriter(w)
+			return zw
+		}},
+	}
+
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			// Test data
+			method := http.MethodGet
+			reqUrl := "http://localhost/books"
+			responseJson := map[string]string{"title": "A book"}
+			compressed := new(bytes.Buffer)
+			w := test.writer(compressed)
+			_, _ = w.Write([]byte(`{"title":"A book"}`))
+			_ = w.Close()
+
+			// Mocking http calls
+			var acceptEncoding string
+			httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+				acceptEncoding = request.Header.Get("Accept-Encoding")
+				response := httpmock.NewBytesResponse(http.StatusOK, compressed.Bytes())
+				response.Header.Set("Content-Encoding", test.encoding)
+				return response, nil
+			})
+
+			// Run test target
+			actualResponseJson := make(map[string]string)
+			err := Get(reqUrl).ResponseJson(&actualResponseJson).Exec()
+
+			// Assertions
+			assert.NoError(suite.T(), err, "should be run without error")
+			assert.Equal(suite.T(), "gzip, deflate, br, zstd", acceptEncoding, "should negotiate encodings")
+			assert.Equal(suite.T(), responseJson, actualResponseJson, "should be equal response")
+		})
+	}
+}
+
+func (suite *RequestsSuite) TestResponseLimit() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/books"
+	compressed := new(bytes.Buffer)
+	w := gzip.NewWriter(compressed)
+	_, _ = w.Write(make([]byte, 1<<20))
+	_ = w.Close()
+
+	// Mocking http calls
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		response := httpmock.NewBytesResponse(http.StatusOK, compressed.Bytes())
+		response.Header.Set("Content-Encoding", EncodingGzip)
+		return response, nil
+	})
+
+	// Run test target
+	var limited, unlimited, optOut []byte
+	limitedErr := Get(reqUrl).ResponseLimit(1024).ResponseRaw(&limited).Exec()
+	unlimitedErr := Get(reqUrl).ResponseLimit(1 << 20).ResponseRaw(&unlimited).Exec()
+	defaultLimit := DefaultResponseLimit
+	DefaultResponseLimit = 1024
+	defaultErr := Get(reqUrl).ResponseRaw(&limited).Exec()
+	optOutErr := Get(reqUrl).ResponseLimit(0).ResponseRaw(&optOut).Exec()
+	DefaultResponseLimit = defaultLimit
+
+	// Assertions
+	assert.ErrorIs(suite.T(), limitedErr, ErrResponseTooLarge, "should enforce decompressed size")
+	assert.NoError(suite.T(), unlimitedErr, "should be run without error")
+	assert.Len(suite.T(), unlimited, 1<<20, "should be decompressed")
+	assert.ErrorIs(suite.T(), defaultErr, ErrResponseTooLarge, "should enforce default limit")
+	assert.NoError(suite.T(), optOutErr, "should disable limit explicitly")
+	assert.Len(suite.T(), optOut, 1<<20, "should be decompressed")
+}
+
+func (suite *RequestsSuite) TestUnrequestedContentEncoding() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/books"
+	encodings := map[string]string{"utf-8": "UTF-8", "none": "none", "unrequested": EncodingBrotli, "broken": EncodingGzip}
+
+	// Mocking http calls
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		response := httpmock.NewStringResponse(http.StatusOK, "plain")
+		response.Header.Set("Content-Encoding", encodings[request.URL.Query().Get("encoding")])
+		return response, nil
+	})
+
+	// Run test target
+	var utf8Body, noneBody, unrequestedBody []byte
+	utf8Err := Get(reqUrl + "?encoding=utf-8").ResponseRaw(&utf8Body).Exec()
+	noneErr := Get(reqUrl + "?encoding=none").ResponseRaw(&noneBody).Exec()
+	unrequestedErr := Get(reqUrl + "?encoding=unrequested").AcceptEncoding(EncodingGzip).ResponseRaw(&unrequestedBody).Exec()
+	brokenErr := Get(reqUrl + "?encoding=broken").Exec()
+
+	// Assertions
+	assert.NoError(suite.T(), utf8Err, "should ignore unknown encoding")
+	assert.Equal(suite.T(), "plain", string(utf8Body))
+	assert.NoError(suite.T(), noneErr, "should ignore unknown encoding")
+	assert.Equal(suite.T(), "plain", string(noneBody))
+	assert.NoError(suite.T(), unrequestedErr, "should ignore encoding that was not requested")
+	assert.Equal(suite.T(), "plain", string(unrequestedBody))
+	assert.Error(suite.T(), brokenErr, "should fail to decode requested encoding")
+}
+
+func (suite *RequestsSuite) TestAcceptEncoding() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/books"
+
+	// Mocking http calls
+	var acceptEncoding string
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		acceptEncoding = request.Header.Get("Accept-Encoding")
+		return httpmock.NewStringResponse(http.StatusOK, ""), nil
+	})
+
+	// Run test target
+	err := Get(reqUrl).AcceptEncoding(EncodingBrotli).Exec()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), "br", acceptEncoding, "should negotiate encodings")
+}
+
+func (suite *RequestsSuite) TestCompressBody() {
+	tests := []struct {
+		name             string
+		target           func(reqUrl string) RequestsInstance
+		expectedEncoding string
+	}{
+		{name: "json gzip", expectedEncoding: EncodingGzip, target: func(reqUrl string) RequestsInstance {
+			return Post(reqUrl).Json(map[string]string{"event": "started"}).CompressBody(EncodingGzip)
+		}},
+		{name: "form deflate", expectedEncoding: EncodingDeflate, target: func(reqUrl string) RequestsInstance {
+			return Post(reqUrl).Form(url.Values{"event": {"started"}}).CompressBody(EncodingDeflate)
+		}},
+		{name: "stream zstd", expectedEncoding: EncodingZstd, target: func(reqUrl string) RequestsInstance {
+			stream := io.MultiReader(strings.NewReader(strings.Repeat("event", 50)), strings.NewReader(strings.Repeat("event", 50)))
+			return Post(reqUrl).Body(stream, "text/plain").CompressBody(EncodingZstd, 100)
+		}},
+		{name: "below threshold", expectedEncoding: "", target: func(reqUrl string) RequestsInstance {
+			return Post(reqUrl).Data([]byte("event"), "text/plain").CompressBody(EncodingGzip, 1024)
+		}},
+		{name: "stream below threshold", expectedEncoding: "", target: func(reqUrl string) RequestsInstance {
+			return Post(reqUrl).Body(ioutil.NopCloser(strings.NewReader("event")), "text/plain").CompressBody(EncodingGzip, 1024)
+		}},
+	}
+
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			// Test data
+			reqUrl := "http://localhost/telemetry"
+
+			// Mocking http calls
+			var actualEncoding string
+			var actualBody []byte
+			httpmock.RegisterResponder(http.MethodPost, reqUrl, func(request *http.Request) (*http.Response, error) {
+				actualEncoding = request.Header.Get("Content-Encoding")
+				body, err := decodeResponseBody(&http.Response{Header: request.Header, Body: request.Body})
+				if err != nil {
+					return nil, err
+				}
+				actualBody, _ = ioutil.ReadAll(body)
+				return httpmock.NewStringResponse(http.StatusOK, ""), nil
+			})
+
+			// Run test target
+			err := test.target(reqUrl).Exec()
+
+			// Assertions
+			assert.NoError(suite.T(), err, "should be run without error")
+			assert.Equal(suite.T(), test.expectedEncoding, actualEncoding, "should set content encoding")
+			assert.Contains(suite.T(), string(actualBody), "event", "should be decodable")
+		})
+	}
+}
+
+func (suite *RequestsSuite) TestCompressBodyStream() {
+	// Test data
+	httpmock.Deactivate()
+	var transferEncoding []string
+	var contentEncoding string
+	var actualBody []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transferEncoding = r.TransferEncoding
+		contentEncoding = r.Header.Get("Content-Encoding")
+		body, err := decodeResponseBody(&http.Response{Header: r.Header, Body: r.Body})
+		if err == nil {
+			actualBody, _ = ioutil.ReadAll(body)
+		}
+	}))
+	defer srv.Close()
+	reader, writer := io.Pipe()
+	go func() {
+		for i := 0; i < 100; i++ {
+			_, _ = writer.Write([]byte("event"))
+		}
+		_ = writer.Close()
+	}()
+
+	// Run test target
+	err := Post(srv.URL).Body(reader, "text/plain").CompressBody(EncodingZstd, 100).Exec()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), []string{"chunked"}, transferEncoding, "should stream compressed body")
+	assert.Equal(suite.T(), EncodingZstd, contentEncoding, "should set content encoding")
+	assert.Equal(suite.T(), strings.Repeat("event", 100), string(actualBody), "should be decodable")
+}
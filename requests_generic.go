@@ -0,0 +1,60 @@
+package gorequests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Do executes the request and decodes the json response into T, an empty
+// response body leaves the zero value. The returned metadata is nil when no
+// response was received. Do replaces ResponseJson, ResponseRaw and
+// ResponseMeta targets of r.
+func Do[T any](r RequestsInstance) (T, *ResponseMeta, error) {
+	var value T
+	var body []byte
+	meta := &ResponseMeta{}
+	err := r.ResponseRaw(&body).ResponseJson(nil).ResponseMeta(meta).Exec()
+	if meta.StatusCode == 0 {
+		meta = nil
+	}
+	if err != nil {
+		return value, meta, err
+	}
+	if len(body) != 0 {
+		if err := json.Unmarshal(body, &value); err != nil {
+			return value, meta, err
+		}
+	}
+	return value, meta, nil
+}
+
+// GetJSON fetches and decodes a json resource, non 2xx responses fail. The
+// url is formatted with args like in Get.
+func GetJSON[T any](ctx context.Context, url string, args ...any) (T, error) {
+	return doJSON[T](Get(url, args...).Context(ctx))
+}
+
+// PostJSON sends body as json and decodes the json response, the url is
+// formatted with args like in Post.
+func PostJSON[T any](ctx context.Context, body interface{}, url string, args ...any) (T, error) {
+	return doJSON[T](Post(url, args...).Context(ctx).Json(body))
+}
+
+func PutJSON[T any](ctx context.Context, body interface{}, url string, args ...any) (T, error) {
+	return doJSON[T](Put(url, args...).Context(ctx).Json(body))
+}
+
+func PatchJSON[T any](ctx context.Context, body interface{}, url string, args ...any) (T, error) {
+	return doJSON[T](Patch(url, args...).Context(ctx).Json(body))
+}
+
+func doJSON[T any](r RequestsInstance) (T, error) {
+	value, meta, err := Do[T](r.Header("Accept", "application/json"))
+	if meta != nil && (meta.StatusCode < http.StatusOK || meta.StatusCode >= http.StatusMultipleChoices) {
+		var zero T
+		return zero, fmt.Errorf("unexpected response status %s", meta.Status)
+	}
+	return value, err
+}
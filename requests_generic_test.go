@@ -0,0 +1,74 @@
+package gorequests
+
+import (
+	"context"
+	"errors"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+)
+
+type genericBook struct {
+	Id    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+func (suite *RequestsSuite) TestDo() {
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/books/1", httpmock.NewStringResponder(http.StatusOK, `{"id":1,"title":"A book"}`))
+	httpmock.RegisterResponder(http.MethodDelete, "http://localhost/books/1", httpmock.NewStringResponder(http.StatusNoContent, ""))
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/books/2", httpmock.NewErrorResponder(errors.New("connection refused")))
+
+	// Run test target
+	book, meta, err := Do[genericBook](Get("http://localhost/books/%d", 1))
+	deleted, deleteMeta, deleteErr := Do[*genericBook](Delete("http://localhost/books/%d", 1))
+	_, failedMeta, failedErr := Do[genericBook](Get("http://localhost/books/%d", 2))
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), genericBook{Id: 1, Title: "A book"}, book)
+	if assert.NotNil(suite.T(), meta) {
+		assert.Equal(suite.T(), http.StatusOK, meta.StatusCode)
+	}
+	assert.NoError(suite.T(), deleteErr, "should be run without error")
+	assert.Nil(suite.T(), deleted, "should keep zero value for empty body")
+	assert.Equal(suite.T(), http.StatusNoContent, deleteMeta.StatusCode)
+	assert.Error(suite.T(), failedErr, "should be failed")
+	assert.Nil(suite.T(), failedMeta, "should not have metadata without response")
+}
+
+func (suite *RequestsSuite) TestGetJSON() {
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/books", httpmock.NewStringResponder(http.StatusOK, `[{"id":1,"title":"A book"}]`))
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/books/9", httpmock.NewStringResponder(http.StatusNotFound, `not found`))
+
+	// Run test target
+	books, err := GetJSON[[]genericBook](context.Background(), "http://localhost/books")
+	_, notFoundErr := GetJSON[genericBook](context.Background(), "http://localhost/books/%d", 9)
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), []genericBook{{Id: 1, Title: "A book"}}, books)
+	assert.EqualError(suite.T(), notFoundErr, "unexpected response status 404")
+}
+
+func (suite *RequestsSuite) TestPostJSON() {
+	// Mocking http calls
+	var actualBody []byte
+	httpmock.RegisterResponder(http.MethodPost, "http://localhost/books", func(request *http.Request) (*http.Response, error) {
+		actualBody, _ = readRequestBody(request)
+		return httpmock.NewStringResponse(http.StatusCreated, `{"id":2,"title":"B book"}`), nil
+	})
+	httpmock.RegisterResponder(http.MethodPatch, "http://localhost/books/2", httpmock.NewStringResponder(http.StatusOK, `{"id":2,"title":"C book"}`))
+
+	// Run test target
+	created, err := PostJSON[genericBook](context.Background(), genericBook{Title: "B book"}, "http://localhost/books")
+	patched, patchErr := PatchJSON[genericBook](context.Background(), genericBook{Title: "C book"}, "http://localhost/books/%d", 2)
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), genericBook{Id: 2, Title: "B book"}, created)
+	assert.JSONEq(suite.T(), `{"id":0,"title":"B book"}`, string(actualBody))
+	assert.NoError(suite.T(), patchErr, "should format url with args")
+	assert.Equal(suite.T(), genericBook{Id: 2, Title: "C book"}, patched)
+}
@@ -0,0 +1,495 @@
+package gorequests
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Har is a HTTP Archive 1.2 document, see http://www.softwareishard.com/blog/har-12-spec/.
+type Har struct {
+	Log HarLog `json:"log"`
+}
+
+type HarLog struct {
+	Version string     `json:"version"`
+	Creator HarCreator `json:"creator"`
+	Entries []HarEntry `json:"entries"`
+}
+
+type HarCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HarEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HarRequest  `json:"request"`
+	Response        HarResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HarTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Connection      string      `json:"connection,omitempty"`
+}
+
+type HarRequest struct {
+	Method      string         `json:"method"`
+	Url         string         `json:"url"`
+	HttpVersion string         `json:"httpVersion"`
+	Cookies     []HarCookie    `json:"cookies"`
+	Headers     []HarNameValue `json:"headers"`
+	QueryString []HarNameValue `json:"queryString"`
+	PostData    *HarPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type HarResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HttpVersion string         `json:"httpVersion"`
+	Cookies     []HarCookie    `json:"cookies"`
+	Headers     []HarNameValue `json:"headers"`
+	Content     HarContent     `json:"content"`
+	RedirectUrl string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+	Error       string         `json:"_error,omitempty"`
+}
+
+type HarCookie struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Path     string     `json:"path,omitempty"`
+	Domain   string     `json:"domain,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	HttpOnly bool       `json:"httpOnly,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+}
+
+type HarNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HarPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type HarContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HarTimings are in milliseconds, -1 means the phase does not apply.
+type HarTimings struct {
+	Blocked float64 `json:"blocked"`
+	Dns     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	Ssl     float64 `json:"ssl"`
+}
+
+// HarRecorder records every request passing through Exec into a HAR archive.
+type HarRecorder struct {
+	mu          sync.Mutex
+	entries     []HarEntry
+	redaction   *Redaction
+	maxBodySize int
+}
+
+func NewHarRecorder() *HarRecorder {
+	return &HarRecorder{entries: make([]HarEntry, 0), maxBodySize: -1}
+}
+
+// Redaction hides secrets in recorded entries, nothing is hidden by default.
+func (h *HarRecorder) Redaction(redaction *Redaction) *HarRecorder {
+	h.redaction = redaction
+	return h
+}
+
+// MaxBodySize truncates recorded bodies, negative size means unlimited.
+func (h *HarRecorder) MaxBodySize(size int) *HarRecorder {
+	h.maxBodySize = size
+	return h
+}
+
+func (h *HarRecorder) Entries() []HarEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entries := make([]HarEntry, len(h.entries))
+	copy(entries, h.entries)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].StartedDateTime.Before(entries[j].StartedDateTime) })
+	return entries
+}
+
+func (h *HarRecorder) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = make([]HarEntry, 0)
+}
+
+func (h *HarRecorder) Har() *Har {
+	return &Har{Log: HarLog{
+		Version: "1.2",
+		Creator: HarCreator{Name: "gorequests", Version: harCreatorVersion()},
+		Entries: h.Entries(),
+	}}
+}
+
+func (h *HarRecorder) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(h.Har(), "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+func (h *HarRecorder) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := h.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (h *HarRecorder) ClientOverride(c *http.Client) (*http.Client, error) {
+	next := clientTransport(c)
+	c.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return h.roundTrip(next, req)
+	})
+	return c, nil
+}
+
+func (h *HarRecorder) roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	trace := &harTrace{start: time.Now()}
+	entry := HarEntry{
+		StartedDateTime: trace.start,
+		Request:         h.request(req, body),
+	}
+
+	res, err := next.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace())))
+	if err != nil {
+		entry.Response = HarResponse{
+			Cookies:     make([]HarCookie, 0),
+			Headers:     make([]HarNameValue, 0),
+			HeadersSize: -1,
+			BodySize:    -1,
+			Error:       err.Error(),
+		}
+		h.add(entry, trace, time.Now())
+		return nil, err
+	}
+	trace.responded(time.Now())
+	if res.StatusCode == http.StatusSwitchingProtocols {
+		// the body of an upgraded connection is the connection itself
+		entry.Response = h.response(res, nil, 0, -1, false)
+		h.add(entry, trace, time.Now())
+		return res, nil
+	}
+
+	// Bodies that are redacted are captured whole, redaction can not parse a
+	// truncated one.
+	captured := &loggingBody{ReadCloser: res.Body, capture: true, limit: h.maxBodySize}
+	if h.redaction.parsesBody(res.Header.Get("Content-Type")) {
+		captured.limit = redactedCaptureLimit(h.maxBodySize)
+	}
+	captured.finish = func(readErr error) {
+		entry.Response = h.response(res, captured.buf.Bytes(), captured.size, captured.limit, captured.truncated)
+		if readErr != nil && readErr != io.EOF {
+			entry.Response.Error = readErr.Error()
+		}
+		h.add(entry, trace, time.Now())
+	}
+	res.Body = captured
+	return res, nil
+}
+
+func (h *HarRecorder) add(entry HarEntry, trace *harTrace, end time.Time) {
+	entry.Timings = trace.timings(end)
+	entry.Time = milliseconds(end.Sub(trace.start))
+	entry.ServerIPAddress = trace.serverAddress()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, entry)
+}
+
+func (h *HarRecorder) request(req *http.Request, body []byte) HarRequest {
+	header := h.redaction.Header(req.Header)
+	redactedUrl := h.redaction.Url(req.URL)
+	request := HarRequest{
+		Method:      req.Method,
+		Url:         redactedUrl,
+		HttpVersion: harHttpVersion(req.Proto),
+		Cookies:     make([]HarCookie, 0),
+		Headers:     harHeaders(header),
+		QueryString: make([]HarNameValue, 0),
+		HeadersSize: -1,
+		BodySize:    int64(len(body)),
+	}
+	if !h.redaction.redactsHeader("Cookie") {
+		for _, cookie := range req.Cookies() {
+			request.Cookies = append(request.Cookies, HarCookie{Name: cookie.Name, Value: cookie.Value})
+		}
+	}
+	if u, err := req.URL.Parse(redactedUrl); err == nil {
+		request.QueryString = harValues(u.Query())
+	}
+	if len(body) > 0 {
+		contentType := req.Header.Get("Content-Type")
+		text, _ := harText(h.body(contentType, body))
+		request.PostData = &HarPostData{MimeType: contentType, Text: text}
+	}
+	return request
+}
+
+// response converts the response with its captured body, a body truncated at
+// limit is dropped when it has to be redacted.
+func (h *HarRecorder) response(res *http.Response, body []byte, size int64, limit int, truncated bool) HarResponse {
+	header := h.redaction.Header(res.Header)
+	contentType := res.Header.Get("Content-Type")
+	decoded := decodeCaptured(res.Header, body, limit)
+	var text, encoding string
+	if !h.redaction.parsesBody(contentType) || (!truncated && (limit < 0 || len(decoded) <= limit)) {
+		text, encoding = harText(h.body(contentType, decoded))
+	}
+	response := HarResponse{
+		Status:      res.StatusCode,
+		StatusText:  strings.TrimSpace(strings.TrimPrefix(res.Status, strconv.Itoa(res.StatusCode))),
+		HttpVersion: harHttpVersion(res.Proto),
+		Cookies:     make([]HarCookie, 0),
+		Headers:     harHeaders(header),
+		Content: HarContent{
+			Size:     int64(len(decoded)),
+			MimeType: contentType,
+			Text:     text,
+			Encoding: encoding,
+		},
+		RedirectUrl: res.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    size,
+	}
+	if len(response.StatusText) == 0 {
+		response.StatusText = http.StatusText(res.StatusCode)
+	}
+	if !h.redaction.redactsHeader("Set-Cookie") {
+		for _, cookie := range res.Cookies() {
+			harCookie := HarCookie{
+				Name:     cookie.Name,
+				Value:    cookie.Value,
+				Path:     cookie.Path,
+				Domain:   cookie.Domain,
+				HttpOnly: cookie.HttpOnly,
+				Secure:   cookie.Secure,
+			}
+			if !cookie.Expires.IsZero() {
+				expires := cookie.Expires
+				harCookie.Expires = &expires
+			}
+			response.Cookies = append(response.Cookies, harCookie)
+		}
+	}
+	return response
+}
+
+// body redacts the whole body before truncating it, bodies that should be
+// redacted but do not parse are dropped.
+func (h *HarRecorder) body(contentType string, body []byte) []byte {
+	redacted, ok := h.redaction.redact(contentType, body)
+	if !ok {
+		return nil
+	}
+	if h.maxBodySize >= 0 && len(redacted) > h.maxBodySize {
+		return redacted[:h.maxBodySize]
+	}
+	return redacted
+}
+
+// harTrace collects connection timings reported by httptrace.
+type harTrace struct {
+	mu                      sync.Mutex
+	start                   time.Time
+	dnsStart, dnsDone       time.Time
+	connectStart, connected time.Time
+	tlsStart, tlsDone       time.Time
+	gotConn, wrote          time.Time
+	firstByte               time.Time
+	reused                  bool
+	remoteAddr              string
+}
+
+func (t *harTrace) clientTrace() *httptrace.ClientTrace {
+	now := func(field *time.Time) func() {
+		return func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if field.IsZero() {
+				*field = time.Now()
+			}
+		}
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { now(&t.dnsStart)() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { now(&t.dnsDone)() },
+		ConnectStart:         func(string, string) { now(&t.connectStart)() },
+		ConnectDone:          func(string, string, error) { now(&t.connected)() },
+		TLSHandshakeStart:    now(&t.tlsStart),
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { now(&t.tlsDone)() },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { now(&t.wrote)() },
+		GotFirstResponseByte: now(&t.firstByte),
+		GotConn: func(info httptrace.GotConnInfo) {
+			now(&t.gotConn)()
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.reused = info.Reused
+			if info.Conn != nil {
+				t.remoteAddr = info.Conn.RemoteAddr().String()
+			}
+		},
+	}
+}
+
+// responded marks the response headers as received for transports that do
+// not report httptrace events.
+func (t *harTrace) responded(at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.firstByte.IsZero() {
+		t.firstByte = at
+	}
+}
+
+func (t *harTrace) timings(end time.Time) HarTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timings := HarTimings{Blocked: -1, Dns: -1, Connect: -1, Ssl: -1}
+	gotConn := t.gotConn
+	if gotConn.IsZero() {
+		gotConn = t.start
+	}
+	wrote := t.wrote
+	if wrote.IsZero() {
+		wrote = gotConn
+	}
+	firstByte := t.firstByte
+	if firstByte.IsZero() {
+		firstByte = end
+	}
+
+	connecting := time.Duration(0)
+	if !t.dnsStart.IsZero() && !t.dnsDone.IsZero() {
+		timings.Dns = milliseconds(t.dnsDone.Sub(t.dnsStart))
+		connecting += t.dnsDone.Sub(t.dnsStart)
+	}
+	if !t.connectStart.IsZero() && !t.connected.IsZero() {
+		// HAR connect time includes the TLS handshake.
+		connected := t.connected
+		if t.tlsDone.After(connected) {
+			connected = t.tlsDone
+		}
+		timings.Connect = milliseconds(connected.Sub(t.connectStart))
+		connecting += connected.Sub(t.connectStart)
+	}
+	if !t.tlsStart.IsZero() && !t.tlsDone.IsZero() {
+		timings.Ssl = milliseconds(t.tlsDone.Sub(t.tlsStart))
+	}
+	if blocked := gotConn.Sub(t.start) - connecting; !t.gotConn.IsZero() && blocked >= 0 {
+		timings.Blocked = milliseconds(blocked)
+	}
+	timings.Send = milliseconds(wrote.Sub(gotConn))
+	timings.Wait = milliseconds(firstByte.Sub(wrote))
+	timings.Receive = milliseconds(end.Sub(firstByte))
+	return timings
+}
+
+func (t *harTrace) serverAddress() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	host, _, err := net.SplitHostPort(t.remoteAddr)
+	if err != nil {
+		return t.remoteAddr
+	}
+	return host
+}
+
+func milliseconds(d time.Duration) float64 {
+	if d < 0 {
+		return 0
+	}
+	return float64(d) / float64(time.Millisecond)
+}
+
+func harHeaders(header http.Header) []HarNameValue {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := make([]HarNameValue, 0, len(header))
+	for _, name := range names {
+		for _, value := range header[name] {
+			values = append(values, HarNameValue{Name: name, Value: value})
+		}
+	}
+	return values
+}
+
+func harValues(query map[string][]string) []HarNameValue {
+	return harHeaders(http.Header(query))
+}
+
+// harText returns textual bodies as is and binary bodies base64 encoded.
+func harText(body []byte) (string, string) {
+	if len(body) == 0 {
+		return "", ""
+	}
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func harHttpVersion(proto string) string {
+	if len(proto) == 0 {
+		return "HTTP/1.1"
+	}
+	return proto
+}
+
+func harCreatorVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == "github.com/memclutter/gorequests" {
+				return dep.Version
+			}
+		}
+	}
+	return "devel"
+}
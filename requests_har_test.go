@@ -0,0 +1,148 @@
+package gorequests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+func (suite *RequestsSuite) TestHarRecorder() {
+	// Test data
+	recorder := NewHarRecorder().Redaction(DefaultRedaction().Query("token"))
+	compressed := new(bytes.Buffer)
+	w := gzip.NewWriter(compressed)
+	_, _ = w.Write([]byte(`{"id":1}`))
+	_ = w.Close()
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/books/1?token=secret", func(request *http.Request) (*http.Response, error) {
+		res := httpmock.NewBytesResponse(http.StatusOK, compressed.Bytes())
+		res.Header.Set("Content-Type", "application/json")
+		res.Header.Set("Content-Encoding", "gzip")
+		res.Header.Add("Set-Cookie", "session=abc")
+		return res, nil
+	})
+	httpmock.RegisterResponder(http.MethodPost, "http://localhost/books", httpmock.NewStringResponder(http.StatusCreated, ""))
+	httpmock.RegisterResponder(http.MethodDelete, "http://localhost/books/1", httpmock.NewErrorResponder(errors.New("connection refused")))
+
+	// Run test target
+	errGet := Get("http://localhost/books/1?token=secret").Use(recorder).Header("Authorization", "Bearer secret").Exec()
+	errPost := Post("http://localhost/books").Use(recorder).Data([]byte("title=A"), "application/x-www-form-urlencoded").Exec()
+	errDelete := Delete("http://localhost/books/1").Use(recorder).Exec()
+
+	// Prepare assert stats
+	entries := recorder.Entries()
+
+	// Assertions
+	assert.NoError(suite.T(), errGet, "should be run without error")
+	assert.NoError(suite.T(), errPost, "should be run without error")
+	assert.Error(suite.T(), errDelete, "should be failed")
+	if !assert.Len(suite.T(), entries, 3) {
+		return
+	}
+
+	get := entries[0]
+	assert.Equal(suite.T(), "http://localhost/books/1?token=REDACTED", get.Request.Url)
+	assert.Equal(suite.T(), []HarNameValue{{Name: "token", Value: "REDACTED"}}, get.Request.QueryString)
+	assert.Contains(suite.T(), get.Request.Headers, HarNameValue{Name: "Authorization", Value: "REDACTED"})
+	assert.Equal(suite.T(), http.StatusOK, get.Response.Status)
+	assert.Equal(suite.T(), "OK", get.Response.StatusText)
+	assert.Equal(suite.T(), `{"id":1}`, get.Response.Content.Text, "should store decoded content")
+	assert.Equal(suite.T(), int64(8), get.Response.Content.Size)
+	assert.Equal(suite.T(), int64(compressed.Len()), get.Response.BodySize)
+	assert.Empty(suite.T(), get.Response.Cookies, "should hide redacted cookies")
+	assert.Equal(suite.T(), float64(-1), get.Timings.Dns)
+	assert.True(suite.T(), get.Time >= get.Timings.Wait)
+
+	post := entries[1]
+	if assert.NotNil(suite.T(), post.Request.PostData) {
+		assert.Equal(suite.T(), "title=A", post.Request.PostData.Text)
+		assert.Equal(suite.T(), "application/x-www-form-urlencoded", post.Request.PostData.MimeType)
+	}
+	assert.Equal(suite.T(), http.StatusCreated, post.Response.Status)
+
+	assert.Equal(suite.T(), 0, entries[2].Response.Status)
+	assert.Contains(suite.T(), entries[2].Response.Error, "connection refused")
+}
+
+func (suite *RequestsSuite) TestHarRecorderWriteFile() {
+	// Test data
+	recorder := NewHarRecorder().MaxBodySize(4)
+	path := filepath.Join(suite.T().TempDir(), "traffic.har")
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/books", httpmock.NewBytesResponder(http.StatusOK, []byte{0xff, 0xfe, 0xfd, 0xfc, 0xfb}))
+
+	// Run test target
+	err := Get("http://localhost/books").Use(recorder).Exec()
+	writeErr := recorder.WriteFile(path)
+
+	// Prepare assert stats
+	data, _ := ioutil.ReadFile(path)
+	har := Har{}
+	jsonErr := json.Unmarshal(data, &har)
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.NoError(suite.T(), writeErr, "should be written without error")
+	assert.NoError(suite.T(), jsonErr, "should be valid json")
+	assert.Equal(suite.T(), "1.2", har.Log.Version)
+	assert.Equal(suite.T(), "gorequests", har.Log.Creator.Name)
+	if assert.Len(suite.T(), har.Log.Entries, 1) {
+		content := har.Log.Entries[0].Response.Content
+		assert.Equal(suite.T(), "base64", content.Encoding)
+		assert.Equal(suite.T(), "//79/A==", content.Text, "should truncate body")
+	}
+
+	recorder.Reset()
+	assert.Empty(suite.T(), recorder.Entries())
+}
+
+func (suite *RequestsSuite) TestHarRecorderRedactsBeforeTruncate() {
+	// Test data
+	recorder := NewHarRecorder().MaxBodySize(16).Redaction(Redact().JsonFields("token"))
+	responseRaw := `{"padding":"` + strings.Repeat("x", 32) + `","token":"secret"}`
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/large", func(request *http.Request) (*http.Response, error) {
+		res := httpmock.NewStringResponse(http.StatusOK, responseRaw)
+		res.Header.Set("Content-Type", "application/json")
+		return res, nil
+	})
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/broken", func(request *http.Request) (*http.Response, error) {
+		res := httpmock.NewStringResponse(http.StatusOK, `{"token":"secret",`)
+		res.Header.Set("Content-Type", "application/json")
+		return res, nil
+	})
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/oversized", func(request *http.Request) (*http.Response, error) {
+		res := httpmock.NewStringResponse(http.StatusOK, `{"token":"secret","padding":"`+strings.Repeat("x", maxRedactedBodySize)+`"}`)
+		res.Header.Set("Content-Type", "application/json")
+		return res, nil
+	})
+
+	// Run test target
+	err := Get("http://localhost/large").Use(recorder).Exec()
+	errBroken := Get("http://localhost/broken").Use(recorder).Exec()
+	errOversized := Get("http://localhost/oversized").Use(recorder).Exec()
+
+	// Prepare assert stats
+	entries := recorder.Entries()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.NoError(suite.T(), errBroken, "should be run without error")
+	assert.NoError(suite.T(), errOversized, "should be run without error")
+	if assert.Len(suite.T(), entries, 3) {
+		assert.Len(suite.T(), entries[0].Response.Content.Text, 16, "should truncate after redaction")
+		assert.NotContains(suite.T(), entries[0].Response.Content.Text, "secret")
+		assert.Empty(suite.T(), entries[1].Response.Content.Text, "should drop unparsable body")
+		assert.Empty(suite.T(), entries[2].Response.Content.Text, "should drop body over the capture limit")
+	}
+}
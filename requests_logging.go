@@ -0,0 +1,425 @@
+package gorequests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogEntry describes a single request sent through Logging, sensitive data
+// is already redacted.
+type LogEntry struct {
+	Method         string
+	Url            string
+	RequestHeader  http.Header
+	RequestBody    []byte
+	RequestSize    int64
+	StatusCode     int
+	ResponseHeader http.Header
+	ResponseBody   []byte
+	ResponseSize   int64
+	Duration       time.Duration
+	Err            error
+	Curl           string
+}
+
+type Logger interface {
+	LogRequest(ctx context.Context, entry *LogEntry)
+}
+
+type LoggerFunc func(ctx context.Context, entry *LogEntry)
+
+func (f LoggerFunc) LogRequest(ctx context.Context, entry *LogEntry) { f(ctx, entry) }
+
+// StdLogger writes one line per request to a standard library logger.
+func StdLogger(logger *log.Logger) Logger {
+	return LoggerFunc(func(ctx context.Context, entry *LogEntry) {
+		if entry.Err != nil {
+			logger.Printf("%s %s error: %v (%s)", entry.Method, entry.Url, entry.Err, entry.Duration)
+			return
+		}
+		logger.Printf("%s %s %d %dB/%dB (%s)", entry.Method, entry.Url, entry.StatusCode, entry.RequestSize, entry.ResponseSize, entry.Duration)
+	})
+}
+
+// unredactableBody is logged instead of bodies the redaction can not parse.
+const unredactableBody = "[body not logged: redaction failed]"
+
+const oversizedBody = "[body not logged: too large to redact]"
+
+// maxRedactedBodySize limits bodies captured whole for redaction.
+const maxRedactedBodySize = 1 << 20
+
+// Redaction hides sensitive headers, query parameters and json fields.
+type Redaction struct {
+	headers     map[string]bool
+	query       map[string]bool
+	jsonFields  map[string]bool
+	replacement string
+}
+
+func Redact() *Redaction {
+	return &Redaction{
+		headers:     make(map[string]bool),
+		query:       make(map[string]bool),
+		jsonFields:  make(map[string]bool),
+		replacement: "REDACTED",
+	}
+}
+
+// DefaultRedaction hides credentials and cookie headers.
+func DefaultRedaction() *Redaction {
+	return Redact().Headers("Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie")
+}
+
+func (r *Redaction) Headers(names ...string) *Redaction {
+	for _, name := range names {
+		r.headers[http.CanonicalHeaderKey(name)] = true
+	}
+	return r
+}
+
+// Query redacts url query parameters and urlencoded form fields.
+func (r *Redaction) Query(names ...string) *Redaction {
+	for _, name := range names {
+		r.query[strings.ToLower(name)] = true
+	}
+	return r
+}
+
+// JsonFields redacts object fields with the given names at any depth.
+func (r *Redaction) JsonFields(names ...string) *Redaction {
+	for _, name := range names {
+		r.jsonFields[strings.ToLower(name)] = true
+	}
+	return r
+}
+
+func (r *Redaction) Replacement(replacement string) *Redaction {
+	r.replacement = replacement
+	return r
+}
+
+func (r *Redaction) Header(header http.Header) http.Header {
+	redacted := header.Clone()
+	if r == nil {
+		return redacted
+	}
+	for name := range redacted {
+		if r.headers[http.CanonicalHeaderKey(name)] {
+			for i := range redacted[name] {
+				redacted[name][i] = r.replacement
+			}
+		}
+	}
+	return redacted
+}
+
+func (r *Redaction) redactsHeader(name string) bool {
+	return r != nil && r.headers[http.CanonicalHeaderKey(name)]
+}
+
+func (r *Redaction) Url(u *url.URL) string {
+	if r == nil || len(r.query) == 0 || len(u.RawQuery) == 0 {
+		return u.String()
+	}
+	redacted := *u
+	redacted.RawQuery = r.values(u.Query()).Encode()
+	return redacted.String()
+}
+
+// Body redacts json and urlencoded form bodies, other bodies are returned
+// as is. Bodies that fail to parse are returned unchanged.
+func (r *Redaction) Body(contentType string, body []byte) []byte {
+	redacted, _ := r.redact(contentType, body)
+	return redacted
+}
+
+// parsesBody reports whether bodies of the content type are redacted.
+func (r *Redaction) parsesBody(contentType string) bool {
+	if r == nil {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		return len(r.query) != 0
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return len(r.jsonFields) != 0
+	}
+	return false
+}
+
+func (r *Redaction) redact(contentType string, body []byte) ([]byte, bool) {
+	if len(body) == 0 || !r.parsesBody(contentType) {
+		return body, true
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/x-www-form-urlencoded" {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return body, false
+		}
+		return []byte(r.values(values).Encode()), true
+	}
+	return r.json(body)
+}
+
+func (r *Redaction) Json(body []byte) []byte {
+	redacted, _ := r.json(body)
+	return redacted
+}
+
+func (r *Redaction) json(body []byte) ([]byte, bool) {
+	if r == nil || len(r.jsonFields) == 0 {
+		return body, true
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return body, false
+	}
+	redacted, err := json.Marshal(r.jsonValue(value))
+	if err != nil {
+		return body, false
+	}
+	return redacted, true
+}
+
+func (r *Redaction) jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if r.jsonFields[strings.ToLower(key)] {
+				v[key] = r.replacement
+			} else {
+				v[key] = r.jsonValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.jsonValue(item)
+		}
+	}
+	return value
+}
+
+func (r *Redaction) values(values url.Values) url.Values {
+	for name, vv := range values {
+		if r.query[strings.ToLower(name)] {
+			for i := range vv {
+				vv[i] = r.replacement
+			}
+		}
+	}
+	return values
+}
+
+// LoggingMiddleware logs every request passing through Exec.
+type LoggingMiddleware struct {
+	logger      Logger
+	redaction   *Redaction
+	headers     bool
+	bodies      bool
+	maxBodySize int
+	curl        bool
+}
+
+func Logging(logger Logger) *LoggingMiddleware {
+	return &LoggingMiddleware{
+		logger:    logger,
+		redaction: DefaultRedaction(),
+	}
+}
+
+func (l *LoggingMiddleware) Redaction(redaction *Redaction) *LoggingMiddleware {
+	l.redaction = redaction
+	return l
+}
+
+func (l *LoggingMiddleware) Headers(enabled bool) *LoggingMiddleware {
+	l.headers = enabled
+	return l
+}
+
+// Bodies logs request and response bodies truncated to maxSize bytes.
+func (l *LoggingMiddleware) Bodies(maxSize int) *LoggingMiddleware {
+	l.bodies = maxSize > 0
+	l.maxBodySize = maxSize
+	return l
+}
+
+// Curl adds the request rendered as a curl command to log entries.
+func (l *LoggingMiddleware) Curl(enabled bool) *LoggingMiddleware {
+	l.curl = enabled
+	return l
+}
+
+func (l *LoggingMiddleware) ClientOverride(c *http.Client) (*http.Client, error) {
+	next := clientTransport(c)
+	c.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return l.roundTrip(next, req)
+	})
+	return c, nil
+}
+
+func (l *LoggingMiddleware) roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	started := time.Now()
+	entry := &LogEntry{
+		Method:      req.Method,
+		Url:         l.redaction.Url(req.URL),
+		RequestSize: req.ContentLength,
+	}
+	if l.headers {
+		entry.RequestHeader = l.redaction.Header(req.Header)
+	}
+	if l.bodies {
+		body, err := readRequestBody(req)
+		if err != nil {
+			return nil, err
+		}
+		entry.RequestSize = int64(len(body))
+		entry.RequestBody = l.body(req.Header.Get("Content-Type"), body)
+	}
+
+	if l.curl {
+		curl, err := CurlCommand(req, l.redaction)
+		if err != nil {
+			return nil, err
+		}
+		entry.Curl = curl
+	}
+
+	res, err := next.RoundTrip(req)
+	if err != nil {
+		entry.Err = err
+		entry.Duration = time.Since(started)
+		l.logger.LogRequest(req.Context(), entry)
+		return nil, err
+	}
+	entry.StatusCode = res.StatusCode
+	if l.headers {
+		entry.ResponseHeader = l.redaction.Header(res.Header)
+	}
+	if res.StatusCode == http.StatusSwitchingProtocols {
+		// the body of an upgraded connection is the connection itself
+		entry.Duration = time.Since(started)
+		l.logger.LogRequest(req.Context(), entry)
+		return res, nil
+	}
+	// Bodies that are redacted are captured whole up to a limit, redaction
+	// can not parse a truncated one.
+	contentType := res.Header.Get("Content-Type")
+	redacted := l.redaction.parsesBody(contentType)
+	limit := l.maxBodySize
+	if redacted {
+		limit = redactedCaptureLimit(limit)
+	}
+	body := &loggingBody{ReadCloser: res.Body, capture: l.bodies, limit: limit}
+	body.finish = func(readErr error) {
+		entry.Duration = time.Since(started)
+		entry.ResponseSize = body.size
+		if readErr != nil && readErr != io.EOF {
+			entry.Err = readErr
+		}
+		if l.bodies {
+			decoded := decodeCaptured(res.Header, body.buf.Bytes(), limit)
+			if redacted && (body.truncated || len(decoded) > limit) {
+				entry.ResponseBody = []byte(oversizedBody)
+			} else {
+				entry.ResponseBody = l.body(contentType, decoded)
+			}
+		}
+		l.logger.LogRequest(req.Context(), entry)
+	}
+	res.Body = body
+	return res, nil
+}
+
+// body redacts the whole body before truncating it, bodies that should be
+// redacted but do not parse are replaced with a placeholder.
+func (l *LoggingMiddleware) body(contentType string, body []byte) []byte {
+	redacted, ok := l.redaction.redact(contentType, body)
+	if !ok {
+		return []byte(unredactableBody)
+	}
+	if len(redacted) > l.maxBodySize {
+		return redacted[:l.maxBodySize]
+	}
+	return redacted
+}
+
+// redactedCaptureLimit returns the capture limit for bodies that are
+// redacted, at least maxRedactedBodySize unless unlimited.
+func redactedCaptureLimit(maxBodySize int) int {
+	if maxBodySize < 0 || maxBodySize > maxRedactedBodySize {
+		return maxBodySize
+	}
+	return maxRedactedBodySize
+}
+
+// decodeCaptured decodes as much of a captured, possibly truncated, encoded
+// body as possible, reading at most one byte over a non negative limit.
+func decodeCaptured(header http.Header, body []byte, limit int) []byte {
+	if len(header.Get("Content-Encoding")) == 0 {
+		return body
+	}
+	decoded, err := decodeResponseBody(&http.Response{Header: header, Body: ioutil.NopCloser(bytes.NewReader(body))})
+	if err != nil {
+		return nil
+	}
+	defer decoded.Close()
+	var r io.Reader = decoded
+	if limit >= 0 {
+		r = io.LimitReader(decoded, int64(limit)+1)
+	}
+	data, _ := ioutil.ReadAll(r)
+	return data
+}
+
+// loggingBody captures up to limit bytes of the body, or all of it when limit
+// is negative, while it is consumed by Exec and reports once the body is read
+// to the end or closed.
+type loggingBody struct {
+	io.ReadCloser
+	capture   bool
+	limit     int
+	buf       bytes.Buffer
+	truncated bool
+	size      int64
+	once      sync.Once
+	finish    func(err error)
+}
+
+func (b *loggingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if b.capture && n > 0 {
+		rest := n
+		if b.limit >= 0 && b.buf.Len()+n > b.limit {
+			rest = b.limit - b.buf.Len()
+			b.truncated = true
+		}
+		b.buf.Write(p[:rest])
+	}
+	if err != nil {
+		b.once.Do(func() { b.finish(err) })
+	}
+	return n, err
+}
+
+func (b *loggingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.finish(nil) })
+	return err
+}
@@ -0,0 +1,43 @@
+//go:build go1.21
+
+package gorequests
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogLogger writes request logs as structured log/slog records.
+func SlogLogger(logger *slog.Logger) Logger {
+	return LoggerFunc(func(ctx context.Context, entry *LogEntry) {
+		attrs := []slog.Attr{
+			slog.String("method", entry.Method),
+			slog.String("url", entry.Url),
+			slog.Int("status", entry.StatusCode),
+			slog.Duration("duration", entry.Duration),
+			slog.Int64("request_size", entry.RequestSize),
+			slog.Int64("response_size", entry.ResponseSize),
+		}
+		if entry.RequestHeader != nil {
+			attrs = append(attrs, slog.Any("request_header", entry.RequestHeader))
+		}
+		if entry.ResponseHeader != nil {
+			attrs = append(attrs, slog.Any("response_header", entry.ResponseHeader))
+		}
+		if entry.RequestBody != nil {
+			attrs = append(attrs, slog.String("request_body", string(entry.RequestBody)))
+		}
+		if entry.ResponseBody != nil {
+			attrs = append(attrs, slog.String("response_body", string(entry.ResponseBody)))
+		}
+		if len(entry.Curl) != 0 {
+			attrs = append(attrs, slog.String("curl", entry.Curl))
+		}
+		level := slog.LevelInfo
+		if entry.Err != nil {
+			level = slog.LevelError
+			attrs = append(attrs, slog.String("error", entry.Err.Error()))
+		}
+		logger.LogAttrs(ctx, level, "http request", attrs...)
+	})
+}
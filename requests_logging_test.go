@@ -0,0 +1,124 @@
+package gorequests
+
+import (
+	"bytes"
+	"context"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"net/http"
+	"strings"
+)
+
+func (suite *RequestsSuite) TestLogging() {
+	// Test data
+	method := http.MethodPost
+	reqUrl := "http://localhost/users?api_key=secret&page=1"
+	responseRaw := `{"id":1,"name":"John","token":"abc"}`
+	entries := make([]*LogEntry, 0)
+	logger := LoggerFunc(func(ctx context.Context, entry *LogEntry) { entries = append(entries, entry) })
+	logging := Logging(logger).
+		Headers(true).
+		Bodies(16).
+		Redaction(DefaultRedaction().Query("api_key").JsonFields("password", "token"))
+
+	// Mocking http calls
+	httpmock.RegisterResponder(method, "http://localhost/users", httpmock.NewStringResponder(http.StatusCreated, responseRaw))
+
+	// Run test target
+	actualResponseJson := make(map[string]interface{})
+	err := Post(reqUrl).
+		Use(logging).
+		Header("Authorization", "Bearer secret").
+		Json(map[string]string{"password": "secret"}).
+		ResponseJson(&actualResponseJson).
+		Exec()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), "abc", actualResponseJson["token"], "should not consume response body")
+	if assert.Len(suite.T(), entries, 1, "should log once") {
+		entry := entries[0]
+		assert.Equal(suite.T(), method, entry.Method)
+		assert.Equal(suite.T(), "http://localhost/users?api_key=REDACTED&page=1", entry.Url)
+		assert.Equal(suite.T(), http.StatusCreated, entry.StatusCode)
+		assert.Equal(suite.T(), "REDACTED", entry.RequestHeader.Get("Authorization"))
+		assert.Equal(suite.T(), `{"password":"RED`, string(entry.RequestBody))
+		assert.Equal(suite.T(), `{"id":1,"name":"`, string(entry.ResponseBody))
+		assert.Equal(suite.T(), int64(len(responseRaw)), entry.ResponseSize)
+	}
+}
+
+func (suite *RequestsSuite) TestLoggingRedactsBeforeTruncate() {
+	// Test data
+	entries := make([]*LogEntry, 0)
+	logger := LoggerFunc(func(ctx context.Context, entry *LogEntry) { entries = append(entries, entry) })
+	logging := Logging(logger).Bodies(16).Redaction(Redact().JsonFields("token"))
+	responseRaw := `{"padding":"` + strings.Repeat("x", 32) + `","token":"secret"}`
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/large", func(request *http.Request) (*http.Response, error) {
+		res := httpmock.NewStringResponse(http.StatusOK, responseRaw)
+		res.Header.Set("Content-Type", "application/json")
+		return res, nil
+	})
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/broken", func(request *http.Request) (*http.Response, error) {
+		res := httpmock.NewStringResponse(http.StatusOK, `{"token":"secret",`)
+		res.Header.Set("Content-Type", "application/json")
+		return res, nil
+	})
+	oversizedRaw := `{"token":"secret","padding":"` + strings.Repeat("x", maxRedactedBodySize) + `"}`
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/oversized", func(request *http.Request) (*http.Response, error) {
+		res := httpmock.NewStringResponse(http.StatusOK, oversizedRaw)
+		res.Header.Set("Content-Type", "application/json")
+		return res, nil
+	})
+
+	// Run test target
+	var raw, oversized []byte
+	err := Get("http://localhost/large").Use(logging).ResponseRaw(&raw).Exec()
+	errBroken := Get("http://localhost/broken").Use(logging).Exec()
+	errOversized := Get("http://localhost/oversized").Use(logging).ResponseRaw(&oversized).Exec()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.NoError(suite.T(), errBroken, "should be run without error")
+	assert.NoError(suite.T(), errOversized, "should be run without error")
+	assert.Equal(suite.T(), responseRaw, string(raw), "should not change response body")
+	assert.Equal(suite.T(), oversizedRaw, string(oversized), "should not change response body")
+	if assert.Len(suite.T(), entries, 3, "should log three times") {
+		assert.Len(suite.T(), entries[0].ResponseBody, 16, "should truncate after redaction")
+		assert.NotContains(suite.T(), string(entries[0].ResponseBody), "secret")
+		assert.Equal(suite.T(), unredactableBody, string(entries[1].ResponseBody), "should not log unparsable body")
+		assert.Equal(suite.T(), oversizedBody, string(entries[2].ResponseBody), "should not capture body over the limit")
+	}
+}
+
+func (suite *RequestsSuite) TestLoggingError() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/users"
+	buf := new(bytes.Buffer)
+	logging := Logging(StdLogger(log.New(buf, "", 0)))
+
+	// Mocking http calls
+	httpmock.RegisterResponder(method, reqUrl, httpmock.ConnectionFailure)
+
+	// Run test target
+	err := Get(reqUrl).Use(logging).Exec()
+
+	// Assertions
+	assert.Error(suite.T(), err, "should be run with error")
+	assert.Contains(suite.T(), buf.String(), "GET http://localhost/users error: ")
+}
+
+func (suite *RequestsSuite) TestRedactionJson() {
+	// Test data
+	redaction := Redact().JsonFields("secret").Replacement("***")
+
+	// Run test target
+	actual := redaction.Json([]byte(`{"items":[{"secret":"a","id":12345678901234567890}],"Secret":{"nested":true}}`))
+
+	// Assertions
+	assert.JSONEq(suite.T(), `{"items":[{"secret":"***","id":12345678901234567890}],"Secret":"***"}`, string(actual))
+}
@@ -1,8 +1,13 @@
 package gorequests
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"github.com/stretchr/testify/mock"
+	"io/ioutil"
 	"net/http"
+	"sync"
 )
 
 type mockClientOverrideMiddleware struct {
@@ -22,3 +27,129 @@ func (m *mockRequestOverrideMiddleware) RequestOverride(r *http.Request) (*http.
 	args := m.Called(r)
 	return args.Get(0).(*http.Request), args.Error(1)
 }
+
+// MockResponse is a scripted response returned by MockRequests, Json is
+// encoded as the body when Body is empty.
+type MockResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Json       interface{}
+}
+
+// MockRequest is a request built and executed through MockRequests.
+type MockRequest struct {
+	*http.Request
+	Body []byte
+}
+
+// MockRequests builds requests answered from testify expectations instead of
+// the network. Exec is expected with the request method and url:
+//
+//	m := NewMockRequests()
+//	m.On("Exec", http.MethodGet, "http://localhost/books").Return(&MockResponse{Json: books}, nil)
+//	err := FetchBooks(m.Get)
+type MockRequests struct {
+	mock.Mock
+	mu       sync.Mutex
+	requests []*MockRequest
+}
+
+func NewMockRequests() *MockRequests {
+	return &MockRequests{requests: make([]*MockRequest, 0)}
+}
+
+func (m *MockRequests) Requests() RequestsInstance { return Requests().Use(m) }
+
+func (m *MockRequests) Trace(url string, args ...any) RequestsInstance {
+	return m.Requests().Method(http.MethodTrace).Url(url, args...)
+}
+func (m *MockRequests) Connect(url string, args ...any) RequestsInstance {
+	return m.Requests().Method(http.MethodConnect).Url(url, args...)
+}
+func (m *MockRequests) Head(url string, args ...any) RequestsInstance {
+	return m.Requests().Method(http.MethodHead).Url(url, args...)
+}
+func (m *MockRequests) Options(url string, args ...any) RequestsInstance {
+	return m.Requests().Method(http.MethodOptions).Url(url, args...)
+}
+func (m *MockRequests) Get(url string, args ...any) RequestsInstance {
+	return m.Requests().Method(http.MethodGet).Url(url, args...)
+}
+func (m *MockRequests) Post(url string, args ...any) RequestsInstance {
+	return m.Requests().Method(http.MethodPost).Url(url, args...)
+}
+func (m *MockRequests) Put(url string, args ...any) RequestsInstance {
+	return m.Requests().Method(http.MethodPut).Url(url, args...)
+}
+func (m *MockRequests) Delete(url string, args ...any) RequestsInstance {
+	return m.Requests().Method(http.MethodDelete).Url(url, args...)
+}
+func (m *MockRequests) Patch(url string, args ...any) RequestsInstance {
+	return m.Requests().Method(http.MethodPatch).Url(url, args...)
+}
+
+// Recorded returns executed requests in order.
+func (m *MockRequests) Recorded() []*MockRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	requests := make([]*MockRequest, len(m.requests))
+	copy(requests, m.requests)
+	return requests
+}
+
+func (m *MockRequests) ClientOverride(c *http.Client) (*http.Client, error) {
+	c.Transport = roundTripperFunc(m.roundTrip)
+	return c, nil
+}
+
+func (m *MockRequests) roundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	m.requests = append(m.requests, &MockRequest{Request: req, Body: body})
+	m.mu.Unlock()
+
+	args := m.MethodCalled("Exec", req.Method, req.URL.String())
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+	var scripted MockResponse
+	switch v := args.Get(0).(type) {
+	case *MockResponse:
+		scripted = *v
+	case MockResponse:
+		scripted = v
+	}
+
+	header := scripted.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	resBody := scripted.Body
+	if len(resBody) == 0 && scripted.Json != nil {
+		if resBody, err = json.Marshal(scripted.Json); err != nil {
+			return nil, fmt.Errorf("mock response json marshal error: %v", err)
+		}
+		if len(header.Get("Content-Type")) == 0 {
+			header.Set("Content-Type", "application/json")
+		}
+	}
+	statusCode := scripted.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(resBody)),
+		ContentLength: int64(len(resBody)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,71 @@
+package gorequests
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+)
+
+func (suite *RequestsSuite) TestMockRequests() {
+	// Test data
+	type book struct {
+		Id    int    `json:"id"`
+		Title string `json:"title"`
+	}
+	createBook := func(post RequestsShort, title string) (book, error) {
+		created := book{}
+		err := post("http://localhost/books").
+			Header("Authorization", "Bearer token").
+			Json(book{Title: title}).
+			ResponseCodeOk(http.StatusCreated).
+			ResponseJson(&created).
+			Exec()
+		return created, err
+	}
+	m := NewMockRequests()
+
+	// Mocking http calls
+	m.On("Exec", http.MethodPost, "http://localhost/books").
+		Return(&MockResponse{StatusCode: http.StatusCreated, Json: book{Id: 1, Title: "A book"}}, nil).Once()
+	m.On("Exec", http.MethodPost, "http://localhost/books").
+		Return(MockResponse{StatusCode: http.StatusConflict, Body: []byte("exists")}, nil).Once()
+
+	// Run test target
+	created, err := createBook(m.Post, "A book")
+	_, errConflict := createBook(m.Post, "A book")
+
+	// Prepare assert stats
+	recorded := m.Recorded()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Error(suite.T(), errConflict, "should be failed")
+	assert.Equal(suite.T(), book{Id: 1, Title: "A book"}, created)
+	m.AssertExpectations(suite.T())
+	m.AssertNumberOfCalls(suite.T(), "Exec", 2)
+	if assert.Len(suite.T(), recorded, 2) {
+		assert.Equal(suite.T(), "Bearer token", recorded[0].Header.Get("Authorization"))
+		assert.JSONEq(suite.T(), `{"id":0,"title":"A book"}`, string(recorded[0].Body))
+	}
+}
+
+func (suite *RequestsSuite) TestMockRequestsError() {
+	// Test data
+	m := NewMockRequests()
+	expectedErr := errors.New("connection refused")
+	meta := &ResponseMeta{}
+
+	// Mocking http calls
+	m.On("Exec", http.MethodGet, mock.Anything).Return(nil, expectedErr)
+	m.On("Exec", http.MethodDelete, "http://localhost/books/1").Return(&MockResponse{StatusCode: http.StatusNoContent}, nil)
+
+	// Run test target
+	errGet := m.Get("http://localhost/books/%d", 1).Exec()
+	errDelete := m.Delete("http://localhost/books/%d", 1).ResponseMeta(meta).Exec()
+
+	// Assertions
+	assert.ErrorIs(suite.T(), errGet, expectedErr)
+	assert.NoError(suite.T(), errDelete, "should be run without error")
+	assert.Equal(suite.T(), http.StatusNoContent, meta.StatusCode)
+}
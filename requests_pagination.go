@@ -0,0 +1,328 @@
+package gorequests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Page is a single response received by Paginator.
+type Page struct {
+	Number int
+	Url    string
+	Body   []byte
+	Meta   *ResponseMeta
+	// Items are the elements of the array selected by Paginator.Items.
+	Items []json.RawMessage
+}
+
+// PaginationStrategy configures next, a fresh copy of the template, to fetch
+// the page after page. Returning nil stops the pagination.
+type PaginationStrategy func(page *Page, next RequestsInstance) (RequestsInstance, error)
+
+// PaginateLink follows RFC 8288 Link headers with rel="next".
+func PaginateLink() PaginationStrategy {
+	return func(page *Page, next RequestsInstance) (RequestsInstance, error) {
+		link, ok := ParseLinkHeader(page.Meta.Header.Values("Link"))["next"]
+		if !ok {
+			return nil, nil
+		}
+		base, err := url.Parse(page.Url)
+		if err != nil {
+			return nil, err
+		}
+		nextUrl, err := base.Parse(link)
+		if err != nil {
+			return nil, fmt.Errorf("pagination link parse error: %v", err)
+		}
+		return next.Url("%s", nextUrl.String()), nil
+	}
+}
+
+// PaginateCursor reads the cursor at the dot separated json path of the
+// response and sends it in the query parameter, an empty or missing cursor
+// stops the pagination.
+func PaginateCursor(path, param string) PaginationStrategy {
+	return func(page *Page, next RequestsInstance) (RequestsInstance, error) {
+		raw, ok := jsonPath(page.Body, path)
+		if !ok {
+			return nil, nil
+		}
+		// numbers are kept as sent, large ids do not fit in a float64
+		var cursor interface{}
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		if err := decoder.Decode(&cursor); err != nil {
+			return nil, err
+		}
+		value := ""
+		switch v := cursor.(type) {
+		case string:
+			value = v
+		case json.Number:
+			value = v.String()
+		}
+		if len(value) == 0 {
+			return nil, nil
+		}
+		return withQuery(page.Url, next, param, value)
+	}
+}
+
+// PaginatePage increments the page number query parameter starting from
+// first until a page without items.
+func PaginatePage(param string, first int) PaginationStrategy {
+	return func(page *Page, next RequestsInstance) (RequestsInstance, error) {
+		if len(page.Items) == 0 {
+			return nil, nil
+		}
+		number, err := queryInt(page.Url, param, first)
+		if err != nil {
+			return nil, err
+		}
+		return withQuery(page.Url, next, param, strconv.Itoa(number+1))
+	}
+}
+
+// PaginateOffset advances the offset query parameter by the number of items
+// received until a page without items.
+func PaginateOffset(param string) PaginationStrategy {
+	return func(page *Page, next RequestsInstance) (RequestsInstance, error) {
+		if len(page.Items) == 0 {
+			return nil, nil
+		}
+		offset, err := queryInt(page.Url, param, 0)
+		if err != nil {
+			return nil, err
+		}
+		return withQuery(page.Url, next, param, strconv.Itoa(offset+len(page.Items)))
+	}
+}
+
+// Paginator executes a request template page by page:
+//
+//	p := Paginate(Get("https://api.github.com/repos/%s/issues", repo), PaginateLink())
+//	for p.Next() {
+//		page := p.Page()
+//	}
+//	err := p.Err()
+type Paginator struct {
+	ctx      context.Context
+	template RequestsInstance
+	strategy PaginationStrategy
+	items    string
+	itemsSet bool
+	maxPages int
+	page     *Page
+	err      error
+	done     bool
+}
+
+func Paginate(template RequestsInstance, strategy PaginationStrategy) *Paginator {
+	return &Paginator{ctx: context.Background(), template: template, strategy: strategy}
+}
+
+func (p *Paginator) Context(ctx context.Context) *Paginator {
+	p.ctx = ctx
+	return p
+}
+
+// MaxPages stops after the given number of pages, zero means no limit.
+func (p *Paginator) MaxPages(maxPages int) *Paginator {
+	p.maxPages = maxPages
+	return p
+}
+
+// Items selects the array of items by dot separated json path, an empty
+// path means the response body is the array.
+func (p *Paginator) Items(path string) *Paginator {
+	p.items, p.itemsSet = path, true
+	return p
+}
+
+// Next fetches the next page and reports whether there is one.
+func (p *Paginator) Next() bool {
+	if p.done {
+		return false
+	}
+	if p.maxPages > 0 && p.page != nil && p.page.Number >= p.maxPages {
+		return p.stop(nil)
+	}
+	if err := p.ctx.Err(); err != nil {
+		return p.stop(err)
+	}
+
+	r := p.template.Clone()
+	if p.page != nil {
+		var err error
+		if r, err = p.strategy(p.page, r); err != nil || r == nil {
+			return p.stop(err)
+		}
+	}
+
+	var body []byte
+	meta := &ResponseMeta{}
+	pageUrl := ""
+	if instance, ok := r.(*requestsInstance); ok {
+		pageUrl = instance.url
+	}
+	err := r.Context(p.ctx).ResponseRaw(&body).ResponseJson(nil).ResponseMeta(meta).Exec()
+	if err != nil {
+		return p.stop(err)
+	}
+	if meta.StatusCode < http.StatusOK || meta.StatusCode >= http.StatusMultipleChoices {
+		return p.stop(fmt.Errorf("unexpected response status %s", meta.Status))
+	}
+
+	page := &Page{Url: pageUrl, Body: body, Meta: meta, Number: 1}
+	if p.page != nil {
+		page.Number = p.page.Number + 1
+	}
+	if p.itemsSet {
+		raw, ok := jsonPath(body, p.items)
+		if ok && !bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			if err := json.Unmarshal(raw, &page.Items); err != nil {
+				return p.stop(fmt.Errorf("pagination items decode error: %v", err))
+			}
+		}
+	}
+	p.page = page
+	return true
+}
+
+func (p *Paginator) stop(err error) bool {
+	p.done, p.err = true, err
+	return false
+}
+
+func (p *Paginator) Page() *Page {
+	return p.page
+}
+
+func (p *Paginator) Err() error {
+	return p.err
+}
+
+// Each calls fn for every page until fn or a request fails.
+func (p *Paginator) Each(fn func(page *Page) error) error {
+	for p.Next() {
+		if err := fn(p.Page()); err != nil {
+			return err
+		}
+	}
+	return p.Err()
+}
+
+// EachItem decodes the items of every page into T and calls fn for each of
+// them, the paginator must select items with Items.
+func EachItem[T any](p *Paginator, fn func(item T) error) error {
+	return p.Each(func(page *Page) error {
+		for _, raw := range page.Items {
+			var item T
+			if err := json.Unmarshal(raw, &item); err != nil {
+				return err
+			}
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func CollectItems[T any](p *Paginator) ([]T, error) {
+	items := make([]T, 0)
+	err := EachItem(p, func(item T) error {
+		items = append(items, item)
+		return nil
+	})
+	return items, err
+}
+
+// ParseLinkHeader returns RFC 8288 link targets by relation type.
+func ParseLinkHeader(values []string) map[string]string {
+	links := make(map[string]string)
+	for _, value := range values {
+		for len(value) > 0 {
+			start := strings.IndexByte(value, '<')
+			end := strings.IndexByte(value, '>')
+			if start < 0 || end < start {
+				break
+			}
+			target := value[start+1 : end]
+			value = value[end+1:]
+			params := value
+			if next := strings.IndexByte(value, '<'); next >= 0 {
+				params, value = value[:next], value[next:]
+			} else {
+				value = ""
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, paramValue, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || !strings.EqualFold(strings.TrimSpace(name), "rel") {
+					continue
+				}
+				paramValue = strings.Trim(strings.TrimSpace(strings.TrimRight(strings.TrimSpace(paramValue), ",")), `"`)
+				for _, rel := range strings.Fields(paramValue) {
+					rel = strings.ToLower(rel)
+					if _, exists := links[rel]; !exists {
+						links[rel] = target
+					}
+				}
+			}
+		}
+	}
+	return links
+}
+
+// jsonPath returns the raw value at a dot separated path of object fields.
+func jsonPath(body []byte, path string) (json.RawMessage, bool) {
+	raw := json.RawMessage(body)
+	if len(path) == 0 {
+		return raw, len(bytes.TrimSpace(raw)) != 0
+	}
+	for _, field := range strings.Split(path, ".") {
+		object := make(map[string]json.RawMessage)
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return nil, false
+		}
+		value, ok := object[field]
+		if !ok {
+			return nil, false
+		}
+		raw = value
+	}
+	return raw, true
+}
+
+func withQuery(pageUrl string, next RequestsInstance, param, value string) (RequestsInstance, error) {
+	u, err := url.Parse(pageUrl)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	query.Set(param, value)
+	u.RawQuery = query.Encode()
+	return next.Url("%s", u.String()), nil
+}
+
+func queryInt(pageUrl, param string, fallback int) (int, error) {
+	u, err := url.Parse(pageUrl)
+	if err != nil {
+		return 0, err
+	}
+	value := u.Query().Get(param)
+	if len(value) == 0 {
+		return fallback, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("pagination parameter %s is not a number: %v", param, err)
+	}
+	return number, nil
+}
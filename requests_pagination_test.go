@@ -0,0 +1,150 @@
+package gorequests
+
+import (
+	"context"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+)
+
+func (suite *RequestsSuite) TestPaginateLink() {
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/issues", func(request *http.Request) (*http.Response, error) {
+		res := httpmock.NewStringResponse(http.StatusOK, `[{"id":1},{"id":2}]`)
+		res.Header.Set("Link", `<http://localhost/issues?page=2>; rel="next", <http://localhost/issues?page=3>; rel="last"`)
+		return res, nil
+	})
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/issues?page=2", func(request *http.Request) (*http.Response, error) {
+		res := httpmock.NewStringResponse(http.StatusOK, `[{"id":3}]`)
+		res.Header.Set("Link", `</issues?page=3>; rel="next"`)
+		return res, nil
+	})
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/issues?page=3", httpmock.NewStringResponder(http.StatusOK, `[]`))
+
+	// Run test target
+	type issue struct {
+		Id int `json:"id"`
+	}
+	issues, err := CollectItems[issue](Paginate(Get("http://localhost/issues").Header("Accept", "application/json"), PaginateLink()).Items(""))
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), []issue{{Id: 1}, {Id: 2}, {Id: 3}}, issues)
+	assert.Equal(suite.T(), 3, httpmock.GetTotalCallCount())
+}
+
+func (suite *RequestsSuite) TestPaginateCursor() {
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/events?limit=2", httpmock.NewStringResponder(http.StatusOK, `{"data":["a","b"],"meta":{"next":"c2"}}`))
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/events?cursor=c2&limit=2", httpmock.NewStringResponder(http.StatusOK, `{"data":["c"],"meta":{"next":null}}`))
+
+	// Run test target
+	events, err := CollectItems[string](Paginate(Get("http://localhost/events?limit=2"), PaginateCursor("meta.next", "cursor")).Items("data"))
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), []string{"a", "b", "c"}, events)
+}
+
+func (suite *RequestsSuite) TestPaginateCursorNumber() {
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/events", httpmock.NewStringResponder(http.StatusOK, `{"data":["a"],"next":12345678901234567890}`))
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/events?cursor=12345678901234567890", httpmock.NewStringResponder(http.StatusOK, `{"data":["b"]}`))
+
+	// Run test target
+	events, err := CollectItems[string](Paginate(Get("http://localhost/events"), PaginateCursor("next", "cursor")).Items("data"))
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), []string{"a", "b"}, events, "should keep numeric cursor exact")
+}
+
+func (suite *RequestsSuite) TestPaginatePageAndOffset() {
+	tests := []struct {
+		name     string
+		strategy PaginationStrategy
+		urls     []string
+	}{
+		{
+			name:     "page",
+			strategy: PaginatePage("page", 1),
+			urls:     []string{"http://localhost/books", "http://localhost/books?page=2", "http://localhost/books?page=3"},
+		},
+		{
+			name:     "offset",
+			strategy: PaginateOffset("offset"),
+			urls:     []string{"http://localhost/books", "http://localhost/books?offset=2", "http://localhost/books?offset=3"},
+		},
+	}
+
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			// Mocking http calls
+			httpmock.Reset()
+			httpmock.RegisterResponder(http.MethodGet, test.urls[0], httpmock.NewStringResponder(http.StatusOK, `{"items":[1,2]}`))
+			httpmock.RegisterResponder(http.MethodGet, test.urls[1], httpmock.NewStringResponder(http.StatusOK, `{"items":[3]}`))
+			httpmock.RegisterResponder(http.MethodGet, test.urls[2], httpmock.NewStringResponder(http.StatusOK, `{"items":[]}`))
+
+			// Run test target
+			pages := make([]int, 0)
+			items, err := CollectItems[int](Paginate(Get("http://localhost/books"), test.strategy).Items("items"))
+			err2 := Paginate(Get("http://localhost/books"), test.strategy).Items("items").MaxPages(2).Each(func(page *Page) error {
+				pages = append(pages, page.Number)
+				return nil
+			})
+
+			// Assertions
+			assert.NoError(suite.T(), err, "should be run without error")
+			assert.NoError(suite.T(), err2, "should be run without error")
+			assert.Equal(suite.T(), []int{1, 2, 3}, items)
+			assert.Equal(suite.T(), []int{1, 2}, pages, "should stop at max pages")
+		})
+	}
+}
+
+func (suite *RequestsSuite) TestPaginateCustom() {
+	// Test data
+	strategy := func(page *Page, next RequestsInstance) (RequestsInstance, error) {
+		if page.Number == 2 {
+			return nil, nil
+		}
+		return next.Method(http.MethodPost).Url("http://localhost/search").Json(map[string]int{"page": page.Number + 1}), nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodPost, "http://localhost/search", httpmock.NewStringResponder(http.StatusOK, `{}`))
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/broken", httpmock.NewStringResponder(http.StatusBadGateway, ``))
+
+	// Run test target
+	pages := 0
+	err := Paginate(Post("http://localhost/search"), strategy).Each(func(page *Page) error {
+		pages++
+		return nil
+	})
+	canceled := Paginate(Post("http://localhost/search"), strategy).Context(ctx)
+	canceledFirst := canceled.Next()
+	cancel()
+	canceledSecond := canceled.Next()
+	brokenErr := Paginate(Get("http://localhost/broken"), PaginateLink()).Each(func(page *Page) error { return nil })
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), 2, pages)
+	assert.True(suite.T(), canceledFirst)
+	assert.False(suite.T(), canceledSecond, "should stop on cancellation")
+	assert.ErrorIs(suite.T(), canceled.Err(), context.Canceled)
+	assert.Error(suite.T(), brokenErr, "should fail on error status")
+}
+
+func (suite *RequestsSuite) TestParseLinkHeader() {
+	// Run test target
+	links := ParseLinkHeader([]string{`<https://api.example.com/items?page=2>; rel="next last", <https://api.example.com/items?page=1>;rel=prev`})
+
+	// Assertions
+	assert.Equal(suite.T(), map[string]string{
+		"next": "https://api.example.com/items?page=2",
+		"last": "https://api.example.com/items?page=2",
+		"prev": "https://api.example.com/items?page=1",
+	}, links)
+}
@@ -0,0 +1,261 @@
+package gorequests
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http/httpproxy"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// forwardProxy answers instead of the target with its name and the
+// requested url.
+func forwardProxy(name string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(name + " " + r.URL.String() + " " + r.Header.Get("Proxy-Authorization")))
+	}))
+}
+
+// socks5Proxy accepts user:pass and tunnels to the requested address.
+func socks5Proxy() (net.Listener, *[]string) {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	targets := make([]string, 0)
+	mu := &sync.Mutex{}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, 262)
+				// greeting, username/password auth is required
+				if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+					return
+				}
+				if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+					return
+				}
+				_, _ = conn.Write([]byte{5, 2})
+				if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+					return
+				}
+				user := make([]byte, buf[1])
+				_, _ = io.ReadFull(conn, user)
+				_, _ = io.ReadFull(conn, buf[:1])
+				pass := make([]byte, buf[0])
+				_, _ = io.ReadFull(conn, pass)
+				if string(user) != "user" || string(pass) != "pass" {
+					_, _ = conn.Write([]byte{1, 1})
+					return
+				}
+				_, _ = conn.Write([]byte{1, 0})
+				// connect request
+				if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+					return
+				}
+				var host string
+				switch buf[3] {
+				case 1:
+					_, _ = io.ReadFull(conn, buf[:4])
+					host = net.IP(buf[:4]).String()
+				case 3:
+					_, _ = io.ReadFull(conn, buf[:1])
+					name := make([]byte, buf[0])
+					_, _ = io.ReadFull(conn, name)
+					host = string(name)
+				default:
+					return
+				}
+				_, _ = io.ReadFull(conn, buf[:2])
+				address := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(buf[:2]))))
+				mu.Lock()
+				targets = append(targets, address)
+				mu.Unlock()
+				target, err := net.Dial("tcp", address)
+				if err != nil {
+					_, _ = conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+					return
+				}
+				defer target.Close()
+				_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				go func() { _, _ = io.Copy(target, conn) }()
+				_, _ = io.Copy(conn, target)
+			}()
+		}
+	}()
+	return listener, &targets
+}
+
+func (suite *RequestsSuite) TestProxy() {
+	// Test data
+	httpmock.Deactivate()
+	proxy := forwardProxy("proxy")
+	defer proxy.Close()
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("direct"))
+	}))
+	defer target.Close()
+	socks, socksTargets := socks5Proxy()
+	defer socks.Close()
+	proxyHost := strings.TrimPrefix(proxy.URL, "http://")
+	session := NewSession().Proxy(proxy.URL).NoProxy("127.0.0.1")
+
+	// Run test target
+	var viaProxy, bypassed, viaSocks, viaSession, sessionBypass, overridden []byte
+	errProxy := Get("http://example.com/path?q=1").Proxy("http://user:pass@" + proxyHost).ResponseRaw(&viaProxy).Exec()
+	errBypass := Get(target.URL).Proxy(proxy.URL).NoProxy("localhost, 127.0.0.0/8").ResponseRaw(&bypassed).Exec()
+	errSocks := Get(target.URL).Proxy("socks5h://user:pass@" + socks.Addr().String()).ResponseRaw(&viaSocks).Exec()
+	errSocksAuth := Get(target.URL).Proxy("socks5://user:wrong@" + socks.Addr().String()).Exec()
+	errSession := session.Get("http://example.com/").ResponseRaw(&viaSession).Exec()
+	errSessionBypass := session.Get(target.URL).ResponseRaw(&sessionBypass).Exec()
+	errOverridden := session.Get(target.URL).Proxy("socks5://user:pass@" + socks.Addr().String()).ResponseRaw(&overridden).Exec()
+	errScheme := Get(target.URL).Proxy("ftp://proxy").Exec()
+
+	// Prepare assert stats
+	targetHost := strings.TrimPrefix(target.URL, "http://")
+
+	// Assertions
+	assert.NoError(suite.T(), errProxy)
+	assert.Equal(suite.T(), "proxy http://example.com/path?q=1 Basic dXNlcjpwYXNz", string(viaProxy))
+	assert.NoError(suite.T(), errBypass)
+	assert.Equal(suite.T(), "direct", string(bypassed))
+	assert.NoError(suite.T(), errSocks)
+	assert.Equal(suite.T(), "direct", string(viaSocks))
+	assert.Error(suite.T(), errSocksAuth, "should fail with invalid socks credentials")
+	assert.NoError(suite.T(), errSession)
+	assert.Equal(suite.T(), "proxy http://example.com/ ", string(viaSession))
+	assert.NoError(suite.T(), errSessionBypass)
+	assert.Equal(suite.T(), "direct", string(sessionBypass))
+	assert.NoError(suite.T(), errOverridden)
+	assert.Equal(suite.T(), "direct", string(overridden))
+	assert.Contains(suite.T(), *socksTargets, targetHost, "should connect through socks proxy")
+	assert.EqualError(suite.T(), errScheme, `proxy scheme "ftp" is not supported`)
+}
+
+func (suite *RequestsSuite) TestProxyEnvironment() {
+	// Test data
+	httpmock.Deactivate()
+	proxy := forwardProxy("env")
+	defer proxy.Close()
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("direct"))
+	}))
+	defer target.Close()
+	defer func(proxyFunc func(*http.Request) (*url.URL, error)) { environmentProxy = proxyFunc }(environmentProxy)
+	proxyFunc := (&httpproxy.Config{HTTPProxy: proxy.URL, NoProxy: "skip.test"}).ProxyFunc()
+	environmentProxy = func(req *http.Request) (*url.URL, error) { return proxyFunc(req.URL) }
+	options := NewTLSOptions()
+
+	// Run test target
+	var proxied, direct []byte
+	errProxied := Get("http://example.test/items").TLS(options).ResponseRaw(&proxied).Exec()
+	errDirect := Get(target.URL).TLS(options).ResponseRaw(&direct).Exec()
+	errSkipped := NewSession().TLS(options).Get("http://skip.test/items").Exec()
+	errNoProxy := Get("http://example.test/items").NoProxy("example.test").Exec()
+	var otherProxied []byte
+	errOtherNoProxy := Get("http://example.test/items").NoProxy("other.test").ResponseRaw(&otherProxied).Exec()
+	errSessionNoProxy := NewSession().TLS(options).NoProxy("example.test").Get("http://example.test/items").Exec()
+
+	// Assertions
+	assert.NoError(suite.T(), errProxied)
+	assert.Equal(suite.T(), "env http://example.test/items ", string(proxied), "should use environment proxy")
+	assert.NoError(suite.T(), errDirect)
+	assert.Equal(suite.T(), "direct", string(direct), "should not proxy localhost")
+	assert.Error(suite.T(), errSkipped, "should connect directly to NO_PROXY hosts")
+	assert.Error(suite.T(), errNoProxy, "should apply NoProxy without Proxy")
+	assert.NoError(suite.T(), errOtherNoProxy)
+	assert.Equal(suite.T(), "env http://example.test/items ", string(otherProxied), "should route requests with NoProxy only")
+	assert.Error(suite.T(), errSessionNoProxy, "should apply NoProxy before environment proxy")
+}
+
+func (suite *RequestsSuite) TestParseNoProxy() {
+	tests := []struct {
+		rules    string
+		url      string
+		expected bool
+	}{
+		{rules: "", url: "http://example.com", expected: false},
+		{rules: "*", url: "http://example.com", expected: true},
+		{rules: "example.com", url: "http://example.com", expected: true},
+		{rules: "example.com", url: "http://api.example.com", expected: true},
+		{rules: "example.com", url: "http://badexample.com", expected: false},
+		{rules: ".example.com", url: "http://example.com", expected: false},
+		{rules: "*.example.com", url: "http://api.example.com", expected: true},
+		{rules: "other.org, example.com:8080", url: "http://example.com", expected: false},
+		{rules: "other.org, example.com:8080", url: "http://example.com:8080", expected: true},
+		{rules: "example.com:443", url: "https://EXAMPLE.com/path", expected: true},
+		{rules: "10.0.0.0/8", url: "http://10.1.2.3:9000", expected: true},
+		{rules: "10.0.0.0/8", url: "http://11.1.2.3", expected: false},
+		{rules: "::1", url: "http://[::1]:8080", expected: true},
+		{rules: "192.168.1.1:80", url: "http://192.168.1.1", expected: true},
+	}
+
+	for _, test := range tests {
+		suite.Run(test.rules+" "+test.url, func() {
+			// Test data
+			u, _ := url.Parse(test.url)
+
+			// Run test target
+			matched := ParseNoProxy(test.rules).Match(u)
+
+			// Assertions
+			assert.Equal(suite.T(), test.expected, matched)
+		})
+	}
+}
+
+func (suite *RequestsSuite) TestProxyPool() {
+	// Test data
+	httpmock.Deactivate()
+	first := forwardProxy("first")
+	defer first.Close()
+	second := forwardProxy("second")
+	defer second.Close()
+	dead := forwardProxy("dead")
+	dead.Close()
+	now := time.Now()
+	pool := NewProxyPool(dead.URL, first.URL, second.URL).NoProxy("internal.local")
+	pool.now = func() time.Time { return now }
+
+	// Run test target
+	names := make([]string, 0)
+	for i := 0; i < 4; i++ {
+		var body []byte
+		if err := Post("http://example.com/").Use(pool).Data([]byte("payload")).ResponseRaw(&body).Exec(); err != nil {
+			names = append(names, err.Error())
+			continue
+		}
+		names = append(names, strings.Fields(string(body))[0])
+	}
+	quarantined := pool.Quarantined()
+	now = now.Add(time.Minute)
+	released := pool.Quarantined()
+	deadPool := NewProxyPool(dead.URL).Random()
+	_, errDead := deadPool.ClientOverride(&http.Client{})
+	errFirst := Get("http://example.com/").Use(deadPool).Exec()
+	errQuarantined := Get("http://example.com/").Use(deadPool).Exec()
+	_, errInvalid := NewProxyPool("http://ok", "gopher://invalid").ClientOverride(&http.Client{})
+	_, errEmpty := NewProxyPool().ClientOverride(&http.Client{})
+
+	// Assertions
+	assert.Equal(suite.T(), []string{"first", "second", "first", "second"}, names, "should skip and quarantine the dead proxy")
+	assert.Equal(suite.T(), []string{dead.URL}, quarantined)
+	assert.Empty(suite.T(), released, "should release proxy after quarantine")
+	assert.NoError(suite.T(), errDead)
+	assert.Error(suite.T(), errFirst, "should return connection error")
+	assert.False(suite.T(), errors.Is(errFirst, ErrNoProxyAvailable))
+	assert.ErrorIs(suite.T(), errQuarantined, ErrNoProxyAvailable)
+	assert.EqualError(suite.T(), errInvalid, `proxy scheme "gopher" is not supported`)
+	assert.EqualError(suite.T(), errEmpty, "proxy pool is empty")
+}
@@ -0,0 +1,229 @@
+package gorequests
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket middleware, requests wait in Exec until a
+// token is available or the request context is done.
+type RateLimiter struct {
+	mu              sync.Mutex
+	rate            float64
+	burst           int
+	limits          map[string]rateLimit
+	key             func(r *http.Request) string
+	buckets         map[string]*tokenBucket
+	adaptive        bool
+	remainingHeader string
+	resetHeader     string
+	now             func() time.Time
+}
+
+type rateLimit struct {
+	rate  float64
+	burst int
+}
+
+type tokenBucket struct {
+	rate         float64
+	burst        float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+	adaptedRate  float64
+	adaptedUntil time.Time
+}
+
+// RateLimit allows perSecond requests per host with bursts up to burst
+// requests.
+func RateLimit(perSecond float64, burst int) *RateLimiter {
+	l := &RateLimiter{
+		rate:            perSecond,
+		burst:           burst,
+		limits:          make(map[string]rateLimit),
+		buckets:         make(map[string]*tokenBucket),
+		remainingHeader: "X-RateLimit-Remaining",
+		resetHeader:     "X-RateLimit-Reset",
+		now:             time.Now,
+	}
+	return l.PerHost()
+}
+
+func (l *RateLimiter) PerHost() *RateLimiter {
+	return l.PerKey(func(r *http.Request) string { return r.URL.Host })
+}
+
+// PerKey groups requests into buckets by the returned key, e.g. an API
+// token or a tenant id.
+func (l *RateLimiter) PerKey(key func(r *http.Request) string) *RateLimiter {
+	l.key = key
+	return l
+}
+
+// Global shares a single bucket between all requests.
+func (l *RateLimiter) Global() *RateLimiter {
+	return l.PerKey(func(r *http.Request) string { return "" })
+}
+
+// Limit overrides rate and burst for a single bucket key.
+func (l *RateLimiter) Limit(key string, perSecond float64, burst int) *RateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits[key] = rateLimit{rate: perSecond, burst: burst}
+	delete(l.buckets, key)
+	return l
+}
+
+// Adaptive slows down buckets using the remaining quota and reset time
+// reported by the server, optionally with custom header names.
+func (l *RateLimiter) Adaptive(headers ...string) *RateLimiter {
+	l.adaptive = true
+	if len(headers) > 0 {
+		l.remainingHeader = headers[0]
+	}
+	if len(headers) > 1 {
+		l.resetHeader = headers[1]
+	}
+	return l
+}
+
+func (l *RateLimiter) Wait(ctx context.Context, key string) error {
+	l.mu.Lock()
+	b := l.bucket(key)
+	now := l.now()
+	b.refill(now)
+	b.tokens--
+	// waiters are spaced by their token debt counted from the end of a block
+	from := now
+	if from.Before(b.blockedUntil) {
+		from = b.blockedUntil
+	}
+	wait := from.Sub(now)
+	if rate := b.currentRate(from); b.tokens < 0 && rate > 0 {
+		wait += time.Duration(-b.tokens / rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		b.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (l *RateLimiter) ClientOverride(c *http.Client) (*http.Client, error) {
+	next := clientTransport(c)
+	c.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		key := l.key(req)
+		if err := l.Wait(req.Context(), key); err != nil {
+			return nil, err
+		}
+		res, err := next.RoundTrip(req)
+		if err == nil && l.adaptive {
+			l.adapt(key, res)
+		}
+		return res, err
+	})
+	return c, nil
+}
+
+func (l *RateLimiter) bucket(key string) *tokenBucket {
+	if b, ok := l.buckets[key]; ok {
+		return b
+	}
+	limit, ok := l.limits[key]
+	if !ok {
+		limit = rateLimit{rate: l.rate, burst: l.burst}
+	}
+	if limit.burst < 1 {
+		limit.burst = 1
+	}
+	b := &tokenBucket{
+		rate:   limit.rate,
+		burst:  float64(limit.burst),
+		tokens: float64(limit.burst),
+		last:   l.now(),
+	}
+	l.buckets[key] = b
+	return b
+}
+
+func (l *RateLimiter) adapt(key string, res *http.Response) {
+	now := l.now()
+	remaining, err := strconv.Atoi(res.Header.Get(l.remainingHeader))
+	reset, ok := parseRateLimitReset(res.Header.Get(l.resetHeader), now)
+	if res.StatusCode == http.StatusTooManyRequests {
+		if retryAfter, retryOk := parseRateLimitReset(res.Header.Get("Retry-After"), now); retryOk {
+			remaining, err, reset, ok = 0, nil, retryAfter, true
+		}
+	}
+	if err != nil || !ok {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.bucket(key)
+	b.refill(now)
+	if remaining <= 0 {
+		// the quota is restored at reset, tokens refill from there
+		b.blockedUntil = reset
+		b.last = reset
+		b.tokens = 1
+		return
+	}
+	if window := reset.Sub(now).Seconds(); window > 0 {
+		if rate := float64(remaining) / window; rate < b.rate {
+			b.adaptedRate = rate
+			b.adaptedUntil = reset
+		}
+	}
+}
+
+func (b *tokenBucket) currentRate(now time.Time) float64 {
+	if b.adaptedRate > 0 && now.Before(b.adaptedUntil) {
+		return b.adaptedRate
+	}
+	return b.rate
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.currentRate(now)
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+}
+
+// parseRateLimitReset accepts both delta seconds and unix timestamps.
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	if len(value) == 0 {
+		return time.Time{}, false
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		if t, err := http.ParseTime(value); err == nil {
+			return t, true
+		}
+		return time.Time{}, false
+	}
+	if seconds > 1e9 {
+		return time.Unix(int64(seconds), 0), true
+	}
+	return now.Add(time.Duration(seconds * float64(time.Second))), true
+}
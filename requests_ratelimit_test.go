@@ -0,0 +1,127 @@
+package gorequests
+
+import (
+	"context"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+func (suite *RequestsSuite) TestRateLimit() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/items"
+	callKey := method + " " + reqUrl
+	limiter := RateLimit(20, 1)
+
+	// Mocking http calls
+	httpmock.RegisterResponder(method, reqUrl, httpmock.NewStringResponder(http.StatusOK, ""))
+
+	// Run test target
+	started := time.Now()
+	errs := make([]error, 0)
+	for i := 0; i < 3; i++ {
+		errs = append(errs, Get(reqUrl).Use(limiter).Exec())
+	}
+	elapsed := time.Since(started)
+
+	// Prepare assert stats
+	httpStats := httpmock.GetCallCountInfo()
+
+	// Assertions
+	assert.Equal(suite.T(), []error{nil, nil, nil}, errs, "should be run without error")
+	assert.Equal(suite.T(), 3, httpStats[callKey], "should be call three times")
+	assert.GreaterOrEqual(suite.T(), elapsed, 90*time.Millisecond, "should wait for tokens")
+}
+
+func (suite *RequestsSuite) TestRateLimitContext() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/items"
+	callKey := method + " " + reqUrl
+	limiter := RateLimit(0.1, 1)
+
+	// Mocking http calls
+	httpmock.RegisterResponder(method, reqUrl, httpmock.NewStringResponder(http.StatusOK, ""))
+
+	// Run test target
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	firstErr := Get(reqUrl).Use(limiter).Context(ctx).Exec()
+	secondErr := Get(reqUrl).Use(limiter).Context(ctx).Exec()
+
+	// Prepare assert stats
+	httpStats := httpmock.GetCallCountInfo()
+
+	// Assertions
+	assert.NoError(suite.T(), firstErr, "should be run without error")
+	assert.ErrorIs(suite.T(), secondErr, context.DeadlineExceeded, "should stop waiting on context")
+	assert.Equal(suite.T(), 1, httpStats[callKey], "should be call once")
+}
+
+func (suite *RequestsSuite) TestRateLimitAdaptive() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost/items"
+	callKey := method + " " + reqUrl
+	limiter := RateLimit(100, 10).Adaptive()
+
+	// Mocking http calls
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		response := httpmock.NewStringResponse(http.StatusOK, "")
+		response.Header.Set("X-RateLimit-Remaining", "0")
+		response.Header.Set("X-RateLimit-Reset", "60")
+		return response, nil
+	})
+
+	// Run test target
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	firstErr := Get(reqUrl).Use(limiter).Context(ctx).Exec()
+	secondErr := Get(reqUrl).Use(limiter).Context(ctx).Exec()
+
+	// Prepare assert stats
+	httpStats := httpmock.GetCallCountInfo()
+
+	// Assertions
+	assert.NoError(suite.T(), firstErr, "should be run without error")
+	assert.ErrorIs(suite.T(), secondErr, context.DeadlineExceeded, "should wait for quota reset")
+	assert.Equal(suite.T(), 1, httpStats[callKey], "should be call once")
+}
+
+func (suite *RequestsSuite) TestRateLimitBlockedWaiters() {
+	// Test data
+	limiter := RateLimit(20, 1).Adaptive()
+	blocked := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	blocked.Header.Set("Retry-After", "0.1")
+
+	// Run test target
+	started := time.Now()
+	limiter.adapt("localhost", blocked)
+	mu := sync.Mutex{}
+	released := make([]time.Duration, 0)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = limiter.Wait(context.Background(), "localhost")
+			mu.Lock()
+			released = append(released, time.Since(started))
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	// Prepare assert stats
+	sort.Slice(released, func(i, j int) bool { return released[i] < released[j] })
+
+	// Assertions
+	assert.GreaterOrEqual(suite.T(), released[0], 90*time.Millisecond, "should wait for quota reset")
+	for i := 1; i < len(released); i++ {
+		assert.GreaterOrEqual(suite.T(), released[i]-released[i-1], 35*time.Millisecond, "should space waiters after reset")
+	}
+}
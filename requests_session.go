@@ -0,0 +1,95 @@
+package gorequests
+
+import (
+	"net/http"
+)
+
+// Session shares state between requests, cookies received in Set-Cookie
+// headers are stored in the session jar and sent with the next requests.
+type Session struct {
+	jar       *CookieJar
+	proxy     string
+	noProxy   []string
+	tls       *TLSOptions
+	transport http.RoundTripper
+	err       error
+}
+
+func NewSession() *Session {
+	return &Session{jar: NewCookieJar()}
+}
+
+func (s *Session) Jar() *CookieJar { return s.jar }
+
+func (s *Session) WithJar(jar *CookieJar) *Session {
+	s.jar = jar
+	return s
+}
+
+// Proxy sends the session requests through the proxy unless the request
+// sets its own, see RequestsInstance.Proxy.
+func (s *Session) Proxy(proxyUrl string) *Session {
+	s.proxy = proxyUrl
+	return s.buildTransport()
+}
+
+func (s *Session) NoProxy(rules ...string) *Session {
+	s.noProxy = append(make([]string, 0, len(rules)), rules...)
+	return s.buildTransport()
+}
+
+// TLS sets the tls options of the session requests unless the request sets
+// its own.
+func (s *Session) TLS(options *TLSOptions) *Session {
+	s.tls = options
+	return s.buildTransport()
+}
+
+func (s *Session) buildTransport() *Session {
+	s.transport, s.err = nil, nil
+	if len(s.proxy) > 0 || len(s.noProxy) > 0 || s.tls != nil {
+		s.transport, s.err = routedTransport(s.proxy, s.noProxy, s.tls)
+	}
+	return s
+}
+
+func (s *Session) ClientOverride(c *http.Client) (*http.Client, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	c.Jar = s.jar
+	if s.transport != nil && c.Transport == nil {
+		c.Transport = s.transport
+	}
+	return c, nil
+}
+
+func (s *Session) Requests() RequestsInstance { return Requests().Use(s) }
+
+func (s *Session) Trace(url string, args ...any) RequestsInstance {
+	return s.Requests().Method(http.MethodTrace).Url(url, args...)
+}
+func (s *Session) Connect(url string, args ...any) RequestsInstance {
+	return s.Requests().Method(http.MethodConnect).Url(url, args...)
+}
+func (s *Session) Head(url string, args ...any) RequestsInstance {
+	return s.Requests().Method(http.MethodHead).Url(url, args...)
+}
+func (s *Session) Options(url string, args ...any) RequestsInstance {
+	return s.Requests().Method(http.MethodOptions).Url(url, args...)
+}
+func (s *Session) Get(url string, args ...any) RequestsInstance {
+	return s.Requests().Method(http.MethodGet).Url(url, args...)
+}
+func (s *Session) Post(url string, args ...any) RequestsInstance {
+	return s.Requests().Method(http.MethodPost).Url(url, args...)
+}
+func (s *Session) Put(url string, args ...any) RequestsInstance {
+	return s.Requests().Method(http.MethodPut).Url(url, args...)
+}
+func (s *Session) Delete(url string, args ...any) RequestsInstance {
+	return s.Requests().Method(http.MethodDelete).Url(url, args...)
+}
+func (s *Session) Patch(url string, args ...any) RequestsInstance {
+	return s.Requests().Method(http.MethodPatch).Url(url, args...)
+}
@@ -0,0 +1,41 @@
+package gorequests
+
+import (
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+)
+
+func (suite *RequestsSuite) TestSessionCookies() {
+	// Test data
+	loginUrl := "http://localhost/login"
+	profileUrl := "http://localhost/profile"
+	callKey := http.MethodGet + " " + profileUrl
+	session := NewSession()
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodPost, loginUrl, func(request *http.Request) (*http.Response, error) {
+		response := httpmock.NewStringResponse(http.StatusOK, "")
+		response.Header.Add("Set-Cookie", (&http.Cookie{Name: "session", Value: "secret", Path: "/"}).String())
+		return response, nil
+	})
+	httpmock.RegisterResponder(http.MethodGet, profileUrl, func(request *http.Request) (*http.Response, error) {
+		if cookie, err := request.Cookie("session"); err == nil && cookie.Value == "secret" {
+			return httpmock.NewStringResponse(http.StatusOK, ""), nil
+		}
+		return httpmock.NewStringResponse(http.StatusUnauthorized, ""), nil
+	})
+
+	// Run test target
+	loginErr := session.Post(loginUrl).Exec()
+	err := session.Get(profileUrl).ResponseCodeOk(http.StatusOK).Exec()
+
+	// Prepare assert stats
+	httpStats := httpmock.GetCallCountInfo()
+
+	// Assertions
+	assert.NoError(suite.T(), loginErr, "should be run without error")
+	assert.NoError(suite.T(), err, "should send session cookie")
+	assert.Equal(suite.T(), 1, httpStats[callKey], "should be call once")
+	assert.Len(suite.T(), session.Jar().All(), 1, "should store cookie")
+}
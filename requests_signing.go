@@ -0,0 +1,171 @@
+package gorequests
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSignatureMissing  = errors.New("signature missing")
+	ErrSignatureMismatch = errors.New("signature mismatch")
+	ErrSignatureExpired  = errors.New("signature timestamp outside tolerance")
+)
+
+// HmacCanonicalizer builds the message that gets signed from the timestamp
+// (empty when timestamps are disabled), the request and its body.
+type HmacCanonicalizer func(timestamp string, r *http.Request, body []byte) []byte
+
+// HmacCanonicalBody signs the raw body only (GitHub style).
+func HmacCanonicalBody(timestamp string, r *http.Request, body []byte) []byte {
+	return body
+}
+
+// HmacCanonicalTimestampBody signs "<timestamp>.<body>" (Stripe style).
+func HmacCanonicalTimestampBody(timestamp string, r *http.Request, body []byte) []byte {
+	return append([]byte(timestamp+"."), body...)
+}
+
+// HmacCanonicalRequest signs method, path with query, timestamp and body
+// separated by new lines.
+func HmacCanonicalRequest(timestamp string, r *http.Request, body []byte) []byte {
+	return append([]byte(strings.Join([]string{r.Method, r.URL.RequestURI(), timestamp, ""}, "\n")), body...)
+}
+
+// HmacSignature signs outgoing requests when passed to Use() and verifies
+// incoming requests signed with the same settings.
+type HmacSignature struct {
+	secret          []byte
+	algorithm       func() hash.Hash
+	signatureHeader string
+	timestampHeader string
+	prefix          string
+	encoding        func([]byte) string
+	canonicalize    HmacCanonicalizer
+	tolerance       time.Duration
+	now             func() time.Time
+}
+
+func Hmac(secret []byte) *HmacSignature {
+	return &HmacSignature{
+		secret:          secret,
+		algorithm:       sha256.New,
+		signatureHeader: "X-Signature",
+		timestampHeader: "X-Signature-Timestamp",
+		encoding:        hex.EncodeToString,
+		canonicalize:    HmacCanonicalTimestampBody,
+		tolerance:       5 * time.Minute,
+		now:             time.Now,
+	}
+}
+
+func HmacGithub(secret []byte) *HmacSignature {
+	return Hmac(secret).
+		Header("X-Hub-Signature-256").
+		TimestampHeader("").
+		Prefix("sha256=").
+		Canonical(HmacCanonicalBody)
+}
+
+func (h *HmacSignature) Algorithm(algorithm func() hash.Hash) *HmacSignature {
+	h.algorithm = algorithm
+	return h
+}
+
+func (h *HmacSignature) Header(name string) *HmacSignature {
+	h.signatureHeader = name
+	return h
+}
+
+// TimestampHeader sets the header carrying the unix timestamp, an empty name
+// disables timestamps.
+func (h *HmacSignature) TimestampHeader(name string) *HmacSignature {
+	h.timestampHeader = name
+	return h
+}
+
+func (h *HmacSignature) Prefix(prefix string) *HmacSignature {
+	h.prefix = prefix
+	return h
+}
+
+func (h *HmacSignature) Encoding(encoding func([]byte) string) *HmacSignature {
+	h.encoding = encoding
+	return h
+}
+
+func (h *HmacSignature) Canonical(canonicalize HmacCanonicalizer) *HmacSignature {
+	h.canonicalize = canonicalize
+	return h
+}
+
+// Tolerance sets the maximum accepted clock skew on verification, zero
+// disables the check.
+func (h *HmacSignature) Tolerance(tolerance time.Duration) *HmacSignature {
+	h.tolerance = tolerance
+	return h
+}
+
+func (h *HmacSignature) Clock(now func() time.Time) *HmacSignature {
+	h.now = now
+	return h
+}
+
+func (h *HmacSignature) Sign(timestamp string, r *http.Request, body []byte) string {
+	mac := hmac.New(h.algorithm, h.secret)
+	mac.Write(h.canonicalize(timestamp, r, body))
+	return h.prefix + h.encoding(mac.Sum(nil))
+}
+
+func (h *HmacSignature) RequestOverride(r *http.Request) (*http.Request, error) {
+	body, err := readRequestBody(r)
+	if err != nil {
+		return nil, fmt.Errorf("request signing body read error: %v", err)
+	}
+	timestamp := ""
+	if len(h.timestampHeader) != 0 {
+		timestamp = strconv.FormatInt(h.now().Unix(), 10)
+		r.Header.Set(h.timestampHeader, timestamp)
+	}
+	r.Header.Set(h.signatureHeader, h.Sign(timestamp, r, body))
+	return r, nil
+}
+
+func (h *HmacSignature) Verify(r *http.Request) error {
+	signature := r.Header.Get(h.signatureHeader)
+	if len(signature) == 0 {
+		return ErrSignatureMissing
+	}
+	timestamp := ""
+	if len(h.timestampHeader) != 0 {
+		timestamp = r.Header.Get(h.timestampHeader)
+		if len(timestamp) == 0 {
+			return ErrSignatureMissing
+		}
+		if h.tolerance > 0 {
+			unix, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				return ErrSignatureExpired
+			}
+			skew := h.now().Sub(time.Unix(unix, 0))
+			if skew > h.tolerance || skew < -h.tolerance {
+				return ErrSignatureExpired
+			}
+		}
+	}
+	body, err := readRequestBody(r)
+	if err != nil {
+		return fmt.Errorf("request signing body read error: %v", err)
+	}
+	if !hmac.Equal([]byte(signature), []byte(h.Sign(timestamp, r, body))) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
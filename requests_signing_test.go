@@ -0,0 +1,75 @@
+package gorequests
+
+import (
+	"bytes"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"time"
+)
+
+func (suite *RequestsSuite) TestHmacSignature() {
+	// Test data
+	method := http.MethodPost
+	reqUrl := "http://localhost/webhooks"
+	callKey := method + " " + reqUrl
+	reqData := []byte(`{"event":"created"}`)
+	signature := Hmac([]byte("secret"))
+
+	// Mocking http calls
+	var verifyErr error
+	var actualBody []byte
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		verifyErr = signature.Verify(request)
+		actualBody, _ = readRequestBody(request)
+		return httpmock.NewStringResponder(http.StatusOK, "")(request)
+	})
+
+	// Run test target
+	err := Post(reqUrl).Data(reqData, "application/json").Use(signature).Exec()
+
+	// Prepare assert stats
+	httpStats := httpmock.GetCallCountInfo()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), 1, httpStats[callKey], "should be call once")
+	assert.NoError(suite.T(), verifyErr, "should be verified")
+	assert.Equal(suite.T(), reqData, actualBody, "should keep body readable")
+}
+
+func (suite *RequestsSuite) TestHmacSignatureGithub() {
+	// Test data, see GitHub webhook documentation
+	request, _ := http.NewRequest(http.MethodPost, "http://localhost", bytes.NewReader([]byte("Hello, World!")))
+	signature := HmacGithub([]byte("It's a Secret to Everybody"))
+
+	// Run test target
+	_, err := signature.RequestOverride(request)
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17", request.Header.Get("X-Hub-Signature-256"))
+	assert.NoError(suite.T(), signature.Verify(request), "should be verified")
+}
+
+func (suite *RequestsSuite) TestHmacSignatureVerifyErr() {
+	// Test data
+	now := time.Unix(1700000000, 0)
+	signer := Hmac([]byte("secret")).Clock(func() time.Time { return now })
+	newRequest := func() *http.Request {
+		request, _ := http.NewRequest(http.MethodPost, "http://localhost", bytes.NewReader([]byte("payload")))
+		_, _ = signer.RequestOverride(request)
+		return request
+	}
+
+	// Run test target
+	missingRequest, _ := http.NewRequest(http.MethodPost, "http://localhost", nil)
+	missingErr := signer.Verify(missingRequest)
+	mismatchErr := Hmac([]byte("other")).Clock(func() time.Time { return now }).Verify(newRequest())
+	expiredErr := Hmac([]byte("secret")).Clock(func() time.Time { return now.Add(time.Hour) }).Verify(newRequest())
+
+	// Assertions
+	assert.ErrorIs(suite.T(), missingErr, ErrSignatureMissing)
+	assert.ErrorIs(suite.T(), mismatchErr, ErrSignatureMismatch)
+	assert.ErrorIs(suite.T(), expiredErr, ErrSignatureExpired)
+}
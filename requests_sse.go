@@ -0,0 +1,262 @@
+package gorequests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrEventStreamClosed is returned by Subscribe when the server answers 204
+// No Content, which tells the client to stop reconnecting.
+var ErrEventStreamClosed = errors.New("event stream closed by server")
+
+// Event is a message received from a text/event-stream.
+type Event struct {
+	Id   string
+	Type string
+	Data string
+}
+
+// EventSource reads Server-Sent Events and reconnects with Last-Event-ID
+// when the connection is lost.
+type EventSource struct {
+	ctx         context.Context
+	template    RequestsInstance
+	retry       time.Duration
+	maxRetries  int
+	lastEventId string
+	onOpen      func()
+}
+
+// NewEventSource uses the request as a template for every connection and
+// waits 3 seconds before reconnecting unless the server sends a retry hint.
+func NewEventSource(r RequestsInstance) *EventSource {
+	return &EventSource{ctx: context.Background(), template: r, retry: 3 * time.Second}
+}
+
+func (s *EventSource) Context(ctx context.Context) *EventSource {
+	s.ctx = ctx
+	return s
+}
+
+func (s *EventSource) Retry(retry time.Duration) *EventSource {
+	s.retry = retry
+	return s
+}
+
+// MaxRetries limits consecutive failed reconnects, zero means unlimited.
+func (s *EventSource) MaxRetries(maxRetries int) *EventSource {
+	s.maxRetries = maxRetries
+	return s
+}
+
+// LastEventId resumes the stream after the given event.
+func (s *EventSource) LastEventId(id string) *EventSource {
+	s.lastEventId = id
+	return s
+}
+
+// OnOpen is called every time a connection is established.
+func (s *EventSource) OnOpen(fn func()) *EventSource {
+	s.onOpen = fn
+	return s
+}
+
+// Subscribe calls fn for every event until the context is done, fn fails,
+// the server closes the stream with 204 No Content or reconnects are
+// exhausted.
+func (s *EventSource) Subscribe(fn func(event *Event) error) error {
+	failures := 0
+	for {
+		connected, err := s.connect(fn)
+		var stop *eventStreamStop
+		if errors.As(err, &stop) {
+			return stop.err
+		}
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+		if connected {
+			failures = 0
+		} else {
+			failures++
+		}
+		if s.maxRetries > 0 && failures > s.maxRetries {
+			return fmt.Errorf("event stream reconnect failed after %d retries: %v", s.maxRetries, err)
+		}
+
+		timer := time.NewTimer(s.retry)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return s.ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Events delivers events on a channel, the error channel receives the
+// Subscribe result once the events channel is closed.
+func (s *EventSource) Events() (<-chan *Event, <-chan error) {
+	events := make(chan *Event)
+	errs := make(chan error, 1)
+	go func() {
+		err := s.Subscribe(func(event *Event) error {
+			select {
+			case events <- event:
+				return nil
+			case <-s.ctx.Done():
+				return s.ctx.Err()
+			}
+		})
+		close(events)
+		errs <- err
+		close(errs)
+	}()
+	return events, errs
+}
+
+// eventStreamStop wraps errors which must not cause a reconnect.
+type eventStreamStop struct {
+	err error
+}
+
+func (e *eventStreamStop) Error() string { return e.err.Error() }
+
+func (s *EventSource) connect(fn func(event *Event) error) (bool, error) {
+	r := s.template.Clone().Context(s.ctx).Header("Accept", "text/event-stream").Header("Cache-Control", "no-cache")
+	if len(s.lastEventId) != 0 {
+		r.Header("Last-Event-ID", s.lastEventId)
+	}
+	instance, ok := r.(*requestsInstance)
+	if !ok {
+		return false, &eventStreamStop{err: fmt.Errorf("event stream requires a gorequests request")}
+	}
+	res, err := instance.do()
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNoContent:
+		return false, &eventStreamStop{err: ErrEventStreamClosed}
+	case res.StatusCode != http.StatusOK:
+		return false, &eventStreamStop{err: fmt.Errorf("event stream unexpected response status %s", res.Status)}
+	}
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType != "text/event-stream" {
+		return false, &eventStreamStop{err: fmt.Errorf("event stream unexpected content type %q", res.Header.Get("Content-Type"))}
+	}
+	body, err := decodeResponseBody(res)
+	if err != nil {
+		return false, &eventStreamStop{err: err}
+	}
+	defer body.Close()
+	if s.onOpen != nil {
+		s.onOpen()
+	}
+
+	err = s.read(body, func(event *Event) error {
+		if err := fn(event); err != nil {
+			return &eventStreamStop{err: err}
+		}
+		return nil
+	})
+	return true, err
+}
+
+// read parses the stream as described in the HTML event stream
+// interpretation rules.
+func (s *EventSource) read(r io.Reader, dispatch func(event *Event) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), 1<<20)
+	scanner.Split(scanEventStreamLines)
+
+	eventType := ""
+	data := new(bytes.Buffer)
+	hasData := false
+	// the id is only used for reconnects once its event is dispatched
+	idBuffer := s.lastEventId
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(line) == 0 {
+			s.lastEventId = idBuffer
+			if hasData {
+				event := &Event{
+					Id:   s.lastEventId,
+					Type: eventType,
+					Data: strings.TrimSuffix(data.String(), "\n"),
+				}
+				if len(event.Type) == 0 {
+					event.Type = "message"
+				}
+				if err := dispatch(event); err != nil {
+					return err
+				}
+			}
+			eventType, hasData = "", false
+			data.Reset()
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				idBuffer = value
+			}
+		case "retry":
+			if milliseconds, err := strconv.ParseUint(value, 10, 63); err == nil {
+				s.retry = time.Duration(milliseconds) * time.Millisecond
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// scanEventStreamLines splits lines ended by CRLF, LF or CR.
+func scanEventStreamLines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		// A CR at the end of the buffer may be followed by LF.
+		return 0, nil, nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,100 @@
+package gorequests
+
+import (
+	"context"
+	"errors"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func eventStreamResponse(body string) *http.Response {
+	res := httpmock.NewStringResponse(http.StatusOK, body)
+	res.Header.Set("Content-Type", "text/event-stream; charset=utf-8")
+	return res
+}
+
+func (suite *RequestsSuite) TestEventSource() {
+	// Mocking http calls
+	lastEventIds := make([]string, 0)
+	calls := 0
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/notifications", func(request *http.Request) (*http.Response, error) {
+		calls++
+		lastEventIds = append(lastEventIds, request.Header.Get("Last-Event-ID"))
+		switch calls {
+		case 1:
+			return eventStreamResponse(": welcome\r\nretry: 10\r\n\r\nid: 1\r\ndata: hello\r\n\r\nevent: update\ndata: line 1\ndata:line 2\nid: 2\n\nid: 9\ndata: incomplete"), nil
+		case 2:
+			return nil, errors.New("connection reset")
+		case 3:
+			return eventStreamResponse("id: 3\rdata: {\"done\":true}\r\r"), nil
+		}
+		return httpmock.NewStringResponse(http.StatusNoContent, ""), nil
+	})
+
+	// Run test target
+	events := make([]Event, 0)
+	opened := 0
+	started := time.Now()
+	err := NewEventSource(Get("http://localhost/notifications")).
+		OnOpen(func() { opened++ }).
+		Subscribe(func(event *Event) error {
+			events = append(events, *event)
+			return nil
+		})
+
+	// Assertions
+	assert.ErrorIs(suite.T(), err, ErrEventStreamClosed)
+	assert.Equal(suite.T(), []Event{
+		{Id: "1", Type: "message", Data: "hello"},
+		{Id: "2", Type: "update", Data: "line 1\nline 2"},
+		{Id: "3", Type: "message", Data: `{"done":true}`},
+	}, events)
+	assert.Equal(suite.T(), []string{"", "2", "2", "3"}, lastEventIds, "should resume from last dispatched event id")
+	assert.Equal(suite.T(), 2, opened)
+	assert.Less(suite.T(), time.Since(started), time.Second, "should honor server retry hint")
+}
+
+func (suite *RequestsSuite) TestEventSourceEvents() {
+	// Test data
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/notifications", func(request *http.Request) (*http.Response, error) {
+		return eventStreamResponse(strings.Repeat("data: ping\n\n", 3)), nil
+	})
+
+	// Run test target
+	events, errs := NewEventSource(Get("http://localhost/notifications")).Context(ctx).Retry(time.Millisecond).Events()
+	received := 0
+	for event := range events {
+		assert.Equal(suite.T(), "ping", event.Data)
+		if received++; received == 5 {
+			cancel()
+		}
+	}
+	err := <-errs
+
+	// Assertions
+	assert.ErrorIs(suite.T(), err, context.Canceled)
+	assert.GreaterOrEqual(suite.T(), received, 5, "should reconnect after end of stream")
+}
+
+func (suite *RequestsSuite) TestEventSourceErrors() {
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/forbidden", httpmock.NewStringResponder(http.StatusForbidden, ""))
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/down", httpmock.NewErrorResponder(errors.New("connection refused")))
+
+	// Run test target
+	noop := func(event *Event) error { return nil }
+	forbiddenErr := NewEventSource(Get("http://localhost/forbidden")).Subscribe(noop)
+	downErr := NewEventSource(Get("http://localhost/down")).Retry(time.Millisecond).MaxRetries(2).Subscribe(noop)
+
+	// Assertions
+	assert.EqualError(suite.T(), forbiddenErr, "event stream unexpected response status 403")
+	assert.Error(suite.T(), downErr, "should be failed")
+	assert.Equal(suite.T(), 3, httpmock.GetCallCountInfo()["GET http://localhost/down"])
+}
@@ -0,0 +1,169 @@
+package gorequests
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+func (r *requestsInstance) execStream(res *http.Response, body io.Reader) error {
+	r.setResponseMeta(res, res.ContentLength)
+	if err := r.streamResponseCode(res, body); err != nil {
+		return err
+	}
+	stream := newJsonStream(body)
+	for {
+		var item json.RawMessage
+		ok, err := stream.next(&item)
+		if err != nil {
+			return fmt.Errorf("response json stream decode error: %v", err)
+		}
+		if !ok {
+			return nil
+		}
+		if err := r.respStream(item); err != nil {
+			return err
+		}
+	}
+}
+
+func (r *requestsInstance) streamResponseCode(res *http.Response, body io.Reader) error {
+	if r.responseCodeAllowed(res.StatusCode) {
+		return nil
+	}
+	prefix := make([]byte, 50)
+	n, _ := io.ReadFull(body, prefix)
+	return responseStatusError(res, prefix[:n])
+}
+
+// jsonStream reads the items of a top level json array or a sequence of
+// whitespace separated values such as NDJSON.
+type jsonStream struct {
+	reader  *bufio.Reader
+	dec     *json.Decoder
+	array   bool
+	started bool
+}
+
+func newJsonStream(r io.Reader) *jsonStream {
+	return &jsonStream{reader: bufio.NewReader(r)}
+}
+
+func (s *jsonStream) next(v interface{}) (bool, error) {
+	if !s.started {
+		s.started = true
+		for {
+			c, err := s.reader.ReadByte()
+			if err == io.EOF {
+				return false, nil
+			} else if err != nil {
+				return false, err
+			}
+			if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+				s.array = c == '['
+				_ = s.reader.UnreadByte()
+				break
+			}
+		}
+		s.dec = json.NewDecoder(s.reader)
+		if s.array {
+			if _, err := s.dec.Token(); err != nil {
+				return false, err
+			}
+		}
+	}
+	if s.dec == nil {
+		return false, nil
+	}
+
+	if s.array {
+		if !s.dec.More() {
+			if _, err := s.dec.Token(); err != nil {
+				return false, err
+			}
+			return false, nil
+		}
+		if err := s.dec.Decode(v); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	if err := s.dec.Decode(v); err == io.EOF {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// JsonIterator decodes items of a streamed json response one at a time:
+//
+//	it, err := IterateJson[Record](Get("https://example.com/export.ndjson"))
+//	defer it.Close()
+//	for it.Next() {
+//		record := it.Value()
+//	}
+//	err = it.Err()
+type JsonIterator[T any] struct {
+	body   io.Closer
+	stream *jsonStream
+	value  T
+	err    error
+	done   bool
+}
+
+// IterateJson sends the request and returns an iterator over the items of
+// the json array or NDJSON response body, the iterator must be closed.
+func IterateJson[T any](r RequestsInstance) (*JsonIterator[T], error) {
+	instance, ok := r.(*requestsInstance)
+	if !ok {
+		return nil, fmt.Errorf("json iterator requires a gorequests request")
+	}
+	res, err := instance.do()
+	if err != nil {
+		return nil, err
+	}
+	body, err := decodeResponseBody(res)
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	instance.setResponseMeta(res, res.ContentLength)
+	if err := instance.streamResponseCode(res, body); err != nil {
+		body.Close()
+		return nil, err
+	}
+	return &JsonIterator[T]{body: body, stream: newJsonStream(body)}, nil
+}
+
+func (it *JsonIterator[T]) Next() bool {
+	if it.done {
+		return false
+	}
+	var value T
+	ok, err := it.stream.next(&value)
+	if err != nil || !ok {
+		it.done = true
+		if err != nil {
+			it.err = fmt.Errorf("response json stream decode error: %v", err)
+		}
+		return false
+	}
+	it.value = value
+	return true
+}
+
+func (it *JsonIterator[T]) Value() T {
+	return it.value
+}
+
+func (it *JsonIterator[T]) Err() error {
+	return it.err
+}
+
+func (it *JsonIterator[T]) Close() error {
+	it.done = true
+	return it.body.Close()
+}
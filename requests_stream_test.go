@@ -0,0 +1,137 @@
+package gorequests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+type streamRecord struct {
+	Id int `json:"id"`
+}
+
+// generatedRecords produces a json array of count records without keeping
+// the body in memory.
+type generatedRecords struct {
+	count, next int
+	buf         []byte
+}
+
+func (g *generatedRecords) Read(p []byte) (int, error) {
+	for len(g.buf) == 0 {
+		switch {
+		case g.next == 0:
+			g.buf = []byte("[")
+		case g.next > g.count:
+			return 0, io.EOF
+		case g.next == g.count:
+			g.buf = []byte(fmt.Sprintf(`{"id":%d}]`, g.next))
+		default:
+			g.buf = []byte(fmt.Sprintf(`{"id":%d},`, g.next))
+		}
+		g.next++
+	}
+	n := copy(p, g.buf)
+	g.buf = g.buf[n:]
+	return n, nil
+}
+
+func (suite *RequestsSuite) TestResponseJsonStream() {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{name: "array", body: " [ {\"id\":1}, [2], \"three\" ]", expected: []string{`{"id":1}`, `[2]`, `"three"`}},
+		{name: "ndjson", body: "{\"id\":1}\n{\"id\":2}\r\n\n3\n", expected: []string{`{"id":1}`, `{"id":2}`, `3`}},
+		{name: "empty array", body: "[]", expected: []string{}},
+		{name: "empty body", body: "", expected: []string{}},
+	}
+
+	for _, test := range tests {
+		suite.Run(test.name, func() {
+			// Mocking http calls
+			httpmock.RegisterResponder(http.MethodGet, "http://localhost/export", httpmock.NewStringResponder(http.StatusOK, test.body))
+
+			// Run test target
+			items := make([]string, 0)
+			err := Get("http://localhost/export").ResponseJsonStream(func(item json.RawMessage) error {
+				items = append(items, string(item))
+				return nil
+			}).Exec()
+
+			// Assertions
+			assert.NoError(suite.T(), err, "should be run without error")
+			assert.Equal(suite.T(), test.expected, items)
+		})
+	}
+}
+
+func (suite *RequestsSuite) TestResponseJsonStreamErrors() {
+	// Test data
+	stopErr := errors.New("stop")
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/export", httpmock.NewStringResponder(http.StatusOK, `[1,2,3]`))
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/broken", httpmock.NewStringResponder(http.StatusOK, `[1,{`))
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/missing", httpmock.NewStringResponder(http.StatusNotFound, `not found`))
+
+	// Run test target
+	calls := 0
+	errStop := Get("http://localhost/export").ResponseJsonStream(func(item json.RawMessage) error {
+		calls++
+		return stopErr
+	}).Exec()
+	errBroken := Get("http://localhost/broken").ResponseJsonStream(func(item json.RawMessage) error { return nil }).Exec()
+	errMissing := Get("http://localhost/missing").ResponseCodeOk(http.StatusOK).ResponseJsonStream(func(item json.RawMessage) error { return nil }).Exec()
+
+	// Assertions
+	assert.ErrorIs(suite.T(), errStop, stopErr)
+	assert.Equal(suite.T(), 1, calls, "should stop on callback error")
+	assert.Error(suite.T(), errBroken, "should fail on invalid json")
+	assert.EqualError(suite.T(), errMissing, "404: not found")
+}
+
+func (suite *RequestsSuite) TestIterateJson() {
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/export", func(request *http.Request) (*http.Response, error) {
+		res := httpmock.NewStringResponse(http.StatusOK, "")
+		res.Body = ioutil.NopCloser(&generatedRecords{count: 100000})
+		return res, nil
+	})
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/records.ndjson", httpmock.NewStringResponder(http.StatusOK, "{\"id\":1}\n{\"id\":\"2\"}\n"))
+
+	// Run test target
+	meta := &ResponseMeta{}
+	it, err := IterateJson[streamRecord](Get("http://localhost/export").ResponseMeta(meta))
+	count, sum := 0, 0
+	if assert.NoError(suite.T(), err, "should be run without error") {
+		for it.Next() {
+			count++
+			sum += it.Value().Id
+		}
+		assert.NoError(suite.T(), it.Err())
+		assert.NoError(suite.T(), it.Close())
+	}
+	invalid, invalidErr := IterateJson[streamRecord](Get("http://localhost/records.ndjson"))
+	invalidItems := make([]streamRecord, 0)
+	if assert.NoError(suite.T(), invalidErr, "should be run without error") {
+		defer invalid.Close()
+		for invalid.Next() {
+			invalidItems = append(invalidItems, invalid.Value())
+		}
+	}
+
+	// Assertions
+	assert.Equal(suite.T(), 100000, count)
+	assert.Equal(suite.T(), 100000*100001/2, sum)
+	assert.Equal(suite.T(), http.StatusOK, meta.StatusCode)
+	assert.Equal(suite.T(), []streamRecord{{Id: 1}}, invalidItems)
+	assert.True(suite.T(), strings.Contains(invalid.Err().Error(), "decode error"), invalid.Err())
+}
@@ -10,6 +10,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -279,6 +280,20 @@ func (suite *RequestsSuite) TestResponseFailCodes() {
 	assert.Equal(suite.T(), 1, httpStats[callKey], "should be call once")
 }
 
+func (suite *RequestsSuite) TestResponseCodeErrorBody() {
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/short", httpmock.NewStringResponder(http.StatusBadGateway, "100% down"))
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/long", httpmock.NewStringResponder(http.StatusNotFound, strings.Repeat("x", 60)))
+
+	// Run test target
+	shortErr := Get("http://localhost/short").ResponseCodeOk(http.StatusOK).Exec()
+	longErr := Get("http://localhost/long").ResponseCodeOk(http.StatusOK).Exec()
+
+	// Assertions
+	assert.EqualError(suite.T(), shortErr, "502: 100% down")
+	assert.EqualError(suite.T(), longErr, "404: "+strings.Repeat("x", 50))
+}
+
 func (suite *RequestsSuite) TestResponseRaw() {
 	// Test data
 	method := http.MethodGet
@@ -466,3 +481,33 @@ func (suite *RequestsSuite) TestUseClientOverrideMiddlewareErr() {
 	assert.Equal(suite.T(), 0, httpStats[callKey], "should be call once")
 	mockMiddleware.AssertExpectations(suite.T())
 }
+
+func (suite *RequestsSuite) TestResponseMeta() {
+	// Test data
+	method := http.MethodGet
+	reqUrl := "http://localhost"
+	callKey := method + " " + reqUrl
+	responseRaw := []byte(`SUCCESS`)
+
+	// Mocking http calls
+	httpmock.RegisterResponder(method, reqUrl, func(request *http.Request) (*http.Response, error) {
+		response := httpmock.NewBytesResponse(http.StatusCreated, responseRaw)
+		response.Header.Set("X-Test", "test")
+		return response, nil
+	})
+
+	// Run test target
+	actualResponseMeta := ResponseMeta{}
+	err := Requests().Url(reqUrl).Method(method).ResponseMeta(&actualResponseMeta).Exec()
+
+	// Prepare assert stats
+	httpStats := httpmock.GetCallCountInfo()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be run without error")
+	assert.Equal(suite.T(), 1, httpStats[callKey], "should be call once")
+	assert.Equal(suite.T(), http.StatusCreated, actualResponseMeta.StatusCode, "should be equal status code")
+	assert.Equal(suite.T(), "test", actualResponseMeta.Header.Get("X-Test"), "should be equal header")
+	assert.Equal(suite.T(), int64(len(responseRaw)), actualResponseMeta.ContentLength, "should be equal length")
+	assert.False(suite.T(), actualResponseMeta.FromCache, "should not be from cache")
+}
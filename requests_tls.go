@@ -0,0 +1,279 @@
+package gorequests
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrPinMismatch = errors.New("certificate public key pin mismatch")
+
+// PinMismatchError is returned when no certificate of the verified chain
+// matches a configured SPKI pin, Pins holds the pins presented by the server.
+type PinMismatchError struct {
+	ServerName string
+	Pins       []string
+}
+
+func (e *PinMismatchError) Error() string {
+	return fmt.Sprintf("certificate public key pin mismatch for %s, got %s", e.ServerName, strings.Join(e.Pins, ", "))
+}
+
+func (e *PinMismatchError) Is(target error) bool { return target == ErrPinMismatch }
+
+func (r *requestsInstance) TLS(options *TLSOptions) RequestsInstance {
+	r.tls = options
+	return r
+}
+
+// TLSOptions configure client certificates, trusted roots and pinning for
+// the requests using them. Options own a transport, so they should be created
+// once and shared to reuse connections. Apart from the client certificate,
+// options can not be changed after the first request or Config call, the
+// requests using them fail after such a change.
+//
+//	options := NewTLSOptions().
+//		ClientCertificate("client.crt", "client.key").
+//		RootCAFile("internal-ca.pem")
+//	err := Get("https://internal.local/status").TLS(options).Exec()
+type TLSOptions struct {
+	mu            sync.Mutex
+	certFile      string
+	keyFile       string
+	certModTime   [2]time.Time
+	cert          *tls.Certificate
+	rootCAs       *x509.CertPool
+	minVersion    uint16
+	serverName    string
+	pins          map[string]bool
+	err           error
+	frozen        bool
+	httpTransport *http.Transport
+}
+
+// NewTLSOptions requires TLS 1.2 and trusts the system roots.
+func NewTLSOptions() *TLSOptions {
+	return &TLSOptions{minVersion: tls.VersionTLS12}
+}
+
+// ClientCertificate loads the PEM encoded certificate and key on the first
+// handshake and reloads them when the files are modified.
+func (o *TLSOptions) ClientCertificate(certFile, keyFile string) *TLSOptions {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.certFile, o.keyFile = certFile, keyFile
+	o.cert = nil
+	o.certModTime = [2]time.Time{}
+	return o
+}
+
+func (o *TLSOptions) ClientKeyPair(cert tls.Certificate) *TLSOptions {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.certFile, o.keyFile = "", ""
+	o.cert = &cert
+	return o
+}
+
+// RootCAs replaces the system roots with the pool.
+func (o *TLSOptions) RootCAs(pool *x509.CertPool) *TLSOptions {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.mutable() {
+		o.rootCAs = pool
+	}
+	return o
+}
+
+// RootCAFile adds PEM encoded certificates to the trusted roots, replacing
+// the system roots.
+func (o *TLSOptions) RootCAFile(files ...string) *TLSOptions {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.mutable() {
+		return o
+	}
+	if o.rootCAs == nil {
+		o.rootCAs = x509.NewCertPool()
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			o.err = fmt.Errorf("root ca read error: %v", err)
+			return o
+		}
+		if !o.rootCAs.AppendCertsFromPEM(data) {
+			o.err = fmt.Errorf("root ca file %s has no certificates", file)
+			return o
+		}
+	}
+	return o
+}
+
+func (o *TLSOptions) MinVersion(version uint16) *TLSOptions {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.mutable() {
+		o.minVersion = version
+	}
+	return o
+}
+
+// ServerName overrides the name sent in SNI and verified against the server
+// certificate.
+func (o *TLSOptions) ServerName(name string) *TLSOptions {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.mutable() {
+		o.serverName = name
+	}
+	return o
+}
+
+// Pin accepts base64 sha256 hashes of the subject public key info, with or
+// without the "sha256/" prefix, as printed by
+//
+//	openssl x509 -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64
+//
+// A certificate of the verified chain has to match one of the pins.
+func (o *TLSOptions) Pin(pins ...string) *TLSOptions {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.mutable() {
+		return o
+	}
+	if o.pins == nil {
+		o.pins = make(map[string]bool)
+	}
+	for _, pin := range pins {
+		o.pins[strings.TrimPrefix(pin, "sha256/")] = true
+	}
+	return o
+}
+
+// Config returns the tls config built from the options, the options can not
+// be changed afterwards.
+func (o *TLSOptions) Config() (*tls.Config, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.config()
+}
+
+func (o *TLSOptions) config() (*tls.Config, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
+	o.frozen = true
+	config := &tls.Config{
+		MinVersion: o.minVersion,
+		RootCAs:    o.rootCAs,
+		ServerName: o.serverName,
+	}
+	config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return o.clientCertificate()
+	}
+	if len(o.pins) > 0 {
+		pins := make(map[string]bool, len(o.pins))
+		for pin := range o.pins {
+			pins[pin] = true
+		}
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			return verifyPins(pins, state)
+		}
+	}
+	return config, nil
+}
+
+// transport returns the transport of the options, created on first use.
+func (o *TLSOptions) transport() (*http.Transport, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.err != nil {
+		return nil, o.err
+	}
+	if o.httpTransport == nil {
+		config, err := o.config()
+		if err != nil {
+			return nil, err
+		}
+		o.httpTransport = newProxyTransport(config)
+	}
+	return o.httpTransport, nil
+}
+
+// mutable reports whether the options can still be changed, it is called
+// with the lock held.
+func (o *TLSOptions) mutable() bool {
+	if o.frozen {
+		o.err = fmt.Errorf("tls options can not be changed after first use")
+		return false
+	}
+	return true
+}
+
+func (o *TLSOptions) clientCertificate() (*tls.Certificate, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.certFile) == 0 {
+		if o.cert == nil {
+			return &tls.Certificate{}, nil
+		}
+		return o.cert, nil
+	}
+	var modTime [2]time.Time
+	for i, file := range []string{o.certFile, o.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return o.reloadFailed(fmt.Errorf("client certificate stat error: %v", err))
+		}
+		modTime[i] = info.ModTime()
+	}
+	if o.cert != nil && modTime == o.certModTime {
+		return o.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(o.certFile, o.keyFile)
+	if err != nil {
+		return o.reloadFailed(fmt.Errorf("client certificate load error: %v", err))
+	}
+	o.cert, o.certModTime = &cert, modTime
+	return o.cert, nil
+}
+
+// reloadFailed keeps the loaded certificate while files are being replaced.
+func (o *TLSOptions) reloadFailed(err error) (*tls.Certificate, error) {
+	if o.cert != nil {
+		return o.cert, nil
+	}
+	return nil, err
+}
+
+func verifyPins(pins map[string]bool, state tls.ConnectionState) error {
+	chain := state.PeerCertificates
+	if len(state.VerifiedChains) > 0 {
+		chain = state.VerifiedChains[0]
+	}
+	presented := make([]string, 0, len(chain))
+	for _, cert := range chain {
+		pin := SpkiPin(cert)
+		if pins[pin] {
+			return nil
+		}
+		presented = append(presented, pin)
+	}
+	return &PinMismatchError{ServerName: state.ServerName, Pins: presented}
+}
+
+// SpkiPin returns the base64 sha256 hash of the certificate public key info.
+func SpkiPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,119 @@
+package gorequests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// issueCertificate creates a certificate signed by parent, or a self signed
+// ca when parent is nil, and returns it with its PEM encoded key.
+func issueCertificate(commonName string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte, []byte) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+	der, _ := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	cert, _ := x509.ParseCertificate(der)
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+	return cert, key,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func (suite *RequestsSuite) TestTLSOptions() {
+	// Test data
+	httpmock.Deactivate()
+	dir := suite.T().TempDir()
+	ca, caKey, _, _ := issueCertificate("test ca", nil, nil)
+	_, _, firstCert, firstKey := issueCertificate("first client", ca, caKey)
+	_, _, secondCert, secondKey := issueCertificate("second client", ca, caKey)
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	_ = ioutil.WriteFile(certFile, firstCert, 0600)
+	_ = ioutil.WriteFile(keyFile, firstKey, 0600)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca)
+	serverNames := make([]string, 0)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverNames = append(serverNames, r.TLS.ServerName)
+		w.Header().Set("Connection", "close")
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs, MaxVersion: tls.VersionTLS12}
+	srv.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+	serverCaFile := filepath.Join(dir, "server-ca.pem")
+	_ = ioutil.WriteFile(serverCaFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0600)
+	options := NewTLSOptions().ClientCertificate(certFile, keyFile).RootCAFile(serverCaFile).ServerName("example.com")
+	session := NewSession().TLS(options)
+
+	// Run test target
+	var first, second []byte
+	errFirst := Get(srv.URL).TLS(options).ResponseRaw(&first).Exec()
+	_ = ioutil.WriteFile(certFile, secondCert, 0600)
+	_ = ioutil.WriteFile(keyFile, secondKey, 0600)
+	later := time.Now().Add(time.Minute)
+	_ = os.Chtimes(certFile, later, later)
+	_ = os.Chtimes(keyFile, later, later)
+	errSecond := session.Get(srv.URL).ResponseRaw(&second).Exec()
+	errNoCert := Get(srv.URL).TLS(NewTLSOptions().RootCAFile(serverCaFile).ServerName("example.com")).Exec()
+	errUnknownCA := Get(srv.URL).TLS(NewTLSOptions().ClientCertificate(certFile, keyFile)).Exec()
+	errWrongName := Get(srv.URL).TLS(NewTLSOptions().ClientCertificate(certFile, keyFile).RootCAFile(serverCaFile).ServerName("other.test")).Exec()
+	errPinned := Get(srv.URL).TLS(NewTLSOptions().ClientCertificate(certFile, keyFile).RootCAFile(serverCaFile).
+		ServerName("example.com").Pin("sha256/AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=", "sha256/"+SpkiPin(srv.Certificate()))).Exec()
+	errPinMismatch := Get(srv.URL).TLS(NewTLSOptions().ClientCertificate(certFile, keyFile).RootCAFile(serverCaFile).
+		ServerName("example.com").Pin("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")).Exec()
+	errMinVersion := Get(srv.URL).TLS(NewTLSOptions().ClientCertificate(certFile, keyFile).RootCAFile(serverCaFile).
+		ServerName("example.com").MinVersion(tls.VersionTLS13)).Exec()
+	errMissingFile := Get(srv.URL).TLS(NewTLSOptions().RootCAFile(filepath.Join(dir, "missing.pem"))).Exec()
+	errChanged := Get(srv.URL).TLS(options.Pin("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")).Exec()
+
+	// Prepare assert stats
+	pinErr := &PinMismatchError{}
+
+	// Assertions
+	assert.NoError(suite.T(), errFirst)
+	assert.Equal(suite.T(), "first client", string(first))
+	assert.NoError(suite.T(), errSecond)
+	assert.Equal(suite.T(), "second client", string(second), "should reload rotated certificate")
+	assert.Equal(suite.T(), "example.com", serverNames[0], "should send overridden server name")
+	assert.Error(suite.T(), errNoCert, "should fail without client certificate")
+	assert.Error(suite.T(), errUnknownCA, "should not trust server without custom root ca")
+	assert.Error(suite.T(), errWrongName, "should verify overridden server name")
+	assert.NoError(suite.T(), errPinned)
+	assert.ErrorIs(suite.T(), errPinMismatch, ErrPinMismatch)
+	if assert.True(suite.T(), errors.As(errPinMismatch, &pinErr)) {
+		assert.Equal(suite.T(), "example.com", pinErr.ServerName)
+		assert.Contains(suite.T(), pinErr.Pins, SpkiPin(srv.Certificate()))
+	}
+	assert.Error(suite.T(), errMinVersion, "should refuse older tls version")
+	assert.False(suite.T(), errors.Is(errMinVersion, ErrPinMismatch))
+	assert.Error(suite.T(), errMissingFile, "should fail with missing root ca file")
+	assert.ErrorContains(suite.T(), errChanged, "tls options can not be changed after first use")
+}
@@ -0,0 +1,159 @@
+package gorequests
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// connectProxy tunnels CONNECT requests authorized with user:pass.
+func connectProxy(requests chan<- *http.Request) *httptest.Server {
+	return httptest.NewServer(connectProxyHandler(requests))
+}
+
+func connectProxyHandler(requests chan<- *http.Request) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+		if r.Header.Get("Proxy-Authorization") != "Basic dXNlcjpwYXNz" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		target, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			target.Close()
+			return
+		}
+		_, _ = rw.WriteString("HTTP/1.1 200 Connection established\r\n\r\n")
+		_ = rw.Flush()
+		go func() {
+			_, _ = io.Copy(target, rw)
+			target.Close()
+		}()
+		_, _ = io.Copy(conn, target)
+		conn.Close()
+	})
+}
+
+func (suite *RequestsSuite) TestTunnel() {
+	// Test data
+	httpmock.Deactivate()
+	target := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("through tunnel"))
+	}))
+	defer target.Close()
+	requests := make(chan *http.Request, 2)
+	proxy := connectProxy(requests)
+	defer proxy.Close()
+	targetAddr := strings.TrimPrefix(target.URL, "https://")
+	proxyAddr := strings.TrimPrefix(proxy.URL, "http://")
+
+	// Run test target
+	conn, err := Connect("http://user:pass@%s", proxyAddr).Header("X-Trace", "1").Tunnel(targetAddr)
+	if !assert.NoError(suite.T(), err, "should be tunneled without error") {
+		return
+	}
+	defer conn.Close()
+	tlsConn := tls.Client(conn, &tls.Config{RootCAs: target.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs, ServerName: "example.com"})
+	_, errWrite := tlsConn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	res, errRead := http.ReadResponse(bufio.NewReader(tlsConn), nil)
+	var body []byte
+	if errRead == nil {
+		body, _ = ioutil.ReadAll(res.Body)
+		res.Body.Close()
+	}
+	_, unauthorizedErr := Connect("http://%s", proxyAddr).Tunnel(targetAddr)
+	_, invalidErr := Connect("http://%s", proxyAddr).Tunnel("example.com")
+	_, schemeErr := Connect("socks5://%s", proxyAddr).Tunnel(targetAddr)
+
+	// Prepare assert stats
+	connectReq := <-requests
+
+	// Assertions
+	assert.Equal(suite.T(), http.MethodConnect, connectReq.Method)
+	assert.Equal(suite.T(), targetAddr, connectReq.Host)
+	assert.Equal(suite.T(), "1", connectReq.Header.Get("X-Trace"))
+	assert.NoError(suite.T(), errWrite)
+	assert.NoError(suite.T(), errRead)
+	assert.Equal(suite.T(), "through tunnel", string(body))
+	assert.EqualError(suite.T(), unauthorizedErr, "tunnel unexpected response status 407 Proxy Authentication Required")
+	assert.Error(suite.T(), invalidErr, "should reject target without port")
+	assert.Error(suite.T(), schemeErr, "should reject unsupported proxy scheme")
+}
+
+func (suite *RequestsSuite) TestTunnelContext() {
+	// Test data
+	httpmock.Deactivate()
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("through tunnel"))
+	}))
+	defer target.Close()
+	requests := make(chan *http.Request, 1)
+	proxy := connectProxy(requests)
+	defer proxy.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Run test target
+	conn, err := Connect("http://user:pass@%s", strings.TrimPrefix(proxy.URL, "http://")).
+		Context(ctx).
+		Tunnel(strings.TrimPrefix(target.URL, "http://"))
+	if !assert.NoError(suite.T(), err, "should be tunneled without error") {
+		cancel()
+		return
+	}
+	defer conn.Close()
+	cancel()
+	_, errWrite := conn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	res, errRead := http.ReadResponse(bufio.NewReader(conn), nil)
+	var body []byte
+	if errRead == nil {
+		body, _ = ioutil.ReadAll(res.Body)
+		res.Body.Close()
+	}
+
+	// Assertions
+	assert.NoError(suite.T(), errWrite, "should keep tunnel usable after the context is done")
+	assert.NoError(suite.T(), errRead, "should keep tunnel usable after the context is done")
+	assert.Equal(suite.T(), "through tunnel", string(body))
+}
+
+func (suite *RequestsSuite) TestTunnelHttpsProxy() {
+	// Test data
+	httpmock.Deactivate()
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+	requests := make(chan *http.Request, 2)
+	proxy := httptest.NewUnstartedServer(connectProxyHandler(requests))
+	proxy.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	proxy.StartTLS()
+	defer proxy.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(proxy.Certificate())
+	targetAddr := strings.TrimPrefix(target.URL, "http://")
+	proxyUrl := "https://user:pass@" + strings.TrimPrefix(proxy.URL, "https://")
+
+	// Run test target
+	conn, err := Connect(proxyUrl).TLS(NewTLSOptions().RootCAs(roots)).Tunnel(targetAddr)
+	if err == nil {
+		conn.Close()
+	}
+	_, untrustedErr := Connect(proxyUrl).Tunnel(targetAddr)
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should trust proxy with tls options")
+	assert.Error(suite.T(), untrustedErr, "should verify proxy certificate")
+}
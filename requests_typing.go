@@ -1,6 +1,10 @@
 package gorequests
 
 import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
 )
@@ -13,13 +17,26 @@ type RequestOverrideMiddleware interface {
 	RequestOverride(r *http.Request) (*http.Request, error)
 }
 
+// ResponseMeta describes the response received by Exec.
+type ResponseMeta struct {
+	StatusCode    int
+	Status        string
+	Header        http.Header
+	ContentLength int64
+	FromCache     bool
+	Stale         bool
+}
+
 type RequestsShort func(url string, args ...any) RequestsInstance
 
 type RequestsInstance interface {
 	Use(middlewares ...interface{}) RequestsInstance
+	Context(ctx context.Context) RequestsInstance
 	Url(url string, args ...interface{}) RequestsInstance
 	Method(method string) RequestsInstance
 	Data(data []byte, contentType ...string) RequestsInstance
+	Body(body io.Reader, contentType ...string) RequestsInstance
+	CompressBody(encoding string, minSize ...int) RequestsInstance
 	Form(form url.Values) RequestsInstance
 	Json(json interface{}) RequestsInstance
 	Cookies(cookies ...*http.Cookie) RequestsInstance
@@ -28,5 +45,17 @@ type RequestsInstance interface {
 	ResponseCodeFail(codes ...int) RequestsInstance
 	ResponseRaw(responseRaw *[]byte) RequestsInstance
 	ResponseJson(responseJson interface{}) RequestsInstance
+	ResponseMeta(responseMeta *ResponseMeta) RequestsInstance
+	ResponseJsonStream(fn func(item json.RawMessage) error) RequestsInstance
+	ResponseLimit(limit int64) RequestsInstance
+	AcceptEncoding(encodings ...string) RequestsInstance
+	Proxy(proxyUrl string) RequestsInstance
+	NoProxy(rules ...string) RequestsInstance
+	TLS(options *TLSOptions) RequestsInstance
+	Curl(redaction ...*Redaction) (string, error)
+	Clone() RequestsInstance
 	Exec() error
+	ExecAsync() *Future
+	WebSocket() (*WebSocketConn, error)
+	Tunnel(target string) (net.Conn, error)
 }
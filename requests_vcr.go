@@ -0,0 +1,430 @@
+package gorequests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+type VcrMode int
+
+const (
+	// VcrRecordOnce replays an existing cassette and records a new one when
+	// the cassette file does not exist yet.
+	VcrRecordOnce VcrMode = iota
+	// VcrReplay only replays, requests never reach the network.
+	VcrReplay
+	// VcrRecord always sends requests and overwrites the cassette.
+	VcrRecord
+)
+
+var ErrVcrUnmatched = errors.New("no matching cassette interaction")
+
+// VcrUnmatchedError is returned for requests without a recorded interaction
+// in replay and strict modes.
+type VcrUnmatchedError struct {
+	Cassette string
+	Method   string
+	Url      string
+}
+
+func (e *VcrUnmatchedError) Error() string {
+	return fmt.Sprintf("no interaction for %s %s in cassette %s", e.Method, e.Url, e.Cassette)
+}
+
+func (e *VcrUnmatchedError) Is(target error) bool { return target == ErrVcrUnmatched }
+
+// Cassette is the stored list of interactions, saved as json when the file
+// name ends with .json and as yaml otherwise.
+type Cassette struct {
+	Interactions []*CassetteInteraction `json:"interactions" yaml:"interactions"`
+}
+
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request" yaml:"request"`
+	Response CassetteResponse `json:"response" yaml:"response"`
+}
+
+type CassetteRequest struct {
+	Method       string      `json:"method" yaml:"method"`
+	Url          string      `json:"url" yaml:"url"`
+	Header       http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	Body         string      `json:"body,omitempty" yaml:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty" yaml:"bodyEncoding,omitempty"`
+}
+
+type CassetteResponse struct {
+	StatusCode   int         `json:"statusCode" yaml:"statusCode"`
+	Status       string      `json:"status" yaml:"status"`
+	Header       http.Header `json:"header,omitempty" yaml:"header,omitempty"`
+	Body         string      `json:"body,omitempty" yaml:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty" yaml:"bodyEncoding,omitempty"`
+}
+
+func (r *CassetteRequest) BodyBytes() []byte {
+	return cassetteDecodeBody(r.Body, r.BodyEncoding)
+}
+
+func (r *CassetteResponse) BodyBytes() []byte {
+	return cassetteDecodeBody(r.Body, r.BodyEncoding)
+}
+
+// VcrMatcher reports whether a recorded request matches the outgoing one.
+type VcrMatcher func(req *http.Request, body []byte, recorded *CassetteRequest) bool
+
+func VcrMatchMethod(req *http.Request, body []byte, recorded *CassetteRequest) bool {
+	return req.Method == recorded.Method
+}
+
+func VcrMatchUrl(req *http.Request, body []byte, recorded *CassetteRequest) bool {
+	return req.URL.String() == recorded.Url
+}
+
+// VcrMatchBody compares bodies, json bodies are compared semantically.
+func VcrMatchBody(req *http.Request, body []byte, recorded *CassetteRequest) bool {
+	recordedBody := recorded.BodyBytes()
+	if bytes.Equal(body, recordedBody) {
+		return true
+	}
+	var actual, expected interface{}
+	if json.Unmarshal(body, &actual) != nil || json.Unmarshal(recordedBody, &expected) != nil {
+		return false
+	}
+	a, _ := json.Marshal(actual)
+	e, _ := json.Marshal(expected)
+	return bytes.Equal(a, e)
+}
+
+func VcrMatchHeaders(names ...string) VcrMatcher {
+	return func(req *http.Request, body []byte, recorded *CassetteRequest) bool {
+		for _, name := range names {
+			if strings.Join(req.Header.Values(name), ",") != strings.Join(recorded.Header.Values(name), ",") {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Vcr records real interactions to a cassette file and replays them later.
+type Vcr struct {
+	mu        sync.Mutex
+	path      string
+	mode      VcrMode
+	matchers  []VcrMatcher
+	strict    bool
+	redaction *Redaction
+	cassette  *Cassette
+	loaded    bool
+	recording bool
+	played    map[*CassetteInteraction]bool
+}
+
+// NewVcr uses the cassette at path in VcrRecordOnce mode, matching requests
+// by method and url. Recorded credential and cookie headers of requests and
+// responses are redacted.
+func NewVcr(path string) *Vcr {
+	return &Vcr{
+		path:      path,
+		mode:      VcrRecordOnce,
+		matchers:  []VcrMatcher{VcrMatchMethod, VcrMatchUrl},
+		redaction: DefaultRedaction(),
+		played:    make(map[*CassetteInteraction]bool),
+	}
+}
+
+func (v *Vcr) Mode(mode VcrMode) *Vcr {
+	v.mode = mode
+	return v
+}
+
+func (v *Vcr) Matchers(matchers ...VcrMatcher) *Vcr {
+	v.matchers = matchers
+	return v
+}
+
+// Strict fails unmatched requests instead of sending them while replaying
+// and plays every interaction at most once.
+func (v *Vcr) Strict(strict bool) *Vcr {
+	v.strict = strict
+	return v
+}
+
+// Redaction sets the rules applied to recorded headers, url queries and
+// bodies. Requests are matched against the cassette after the same redaction,
+// bodies that can not be redacted are not stored.
+func (v *Vcr) Redaction(redaction *Redaction) *Vcr {
+	v.redaction = redaction
+	return v
+}
+
+func (v *Vcr) Cassette() (*Cassette, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if err := v.load(); err != nil {
+		return nil, err
+	}
+	return v.cassette, nil
+}
+
+// Recording reports whether requests are sent to the network.
+func (v *Vcr) Recording() (bool, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if err := v.load(); err != nil {
+		return false, err
+	}
+	return v.recording, nil
+}
+
+func (v *Vcr) load() error {
+	if v.loaded {
+		return nil
+	}
+	v.cassette = &Cassette{Interactions: make([]*CassetteInteraction, 0)}
+	if v.mode == VcrRecord {
+		v.recording, v.loaded = true, true
+		return nil
+	}
+	data, err := ioutil.ReadFile(v.path)
+	if os.IsNotExist(err) && v.mode == VcrRecordOnce {
+		v.recording, v.loaded = true, true
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("cassette read error: %v", err)
+	}
+	if v.isJson() {
+		err = json.Unmarshal(data, v.cassette)
+	} else {
+		err = yaml.Unmarshal(data, v.cassette)
+	}
+	if err != nil {
+		return fmt.Errorf("cassette %s decode error: %v", v.path, err)
+	}
+	v.loaded = true
+	return nil
+}
+
+func (v *Vcr) isJson() bool {
+	return strings.EqualFold(filepath.Ext(v.path), ".json")
+}
+
+// Save writes the cassette, recorded interactions are saved automatically.
+func (v *Vcr) Save() error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.save()
+}
+
+func (v *Vcr) save() error {
+	if err := v.load(); err != nil {
+		return err
+	}
+	var data []byte
+	var err error
+	if v.isJson() {
+		data, err = json.MarshalIndent(v.cassette, "", "  ")
+	} else {
+		data, err = yaml.Marshal(v.cassette)
+	}
+	if err != nil {
+		return fmt.Errorf("cassette encode error: %v", err)
+	}
+	if dir := filepath.Dir(v.path); len(dir) != 0 {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("cassette write error: %v", err)
+		}
+	}
+	if err := ioutil.WriteFile(v.path, data, 0644); err != nil {
+		return fmt.Errorf("cassette write error: %v", err)
+	}
+	return nil
+}
+
+func (v *Vcr) ClientOverride(c *http.Client) (*http.Client, error) {
+	next := clientTransport(c)
+	c.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return v.roundTrip(next, req)
+	})
+	return c, nil
+}
+
+func (v *Vcr) roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	if err := v.load(); err != nil {
+		v.mu.Unlock()
+		return nil, err
+	}
+	recording := v.recording
+	redactedReq, redactedBody := v.redactRequest(req, body)
+	if !recording {
+		interaction := v.match(redactedReq, redactedBody)
+		v.mu.Unlock()
+		if interaction != nil {
+			return interaction.Response.response(req), nil
+		}
+		if v.strict || v.mode == VcrReplay {
+			return nil, &VcrUnmatchedError{Cassette: v.path, Method: req.Method, Url: redactedReq.URL.String()}
+		}
+	} else {
+		v.mu.Unlock()
+	}
+
+	res, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusSwitchingProtocols {
+		// upgraded connections can not be replayed
+		return res, nil
+	}
+	resBody, err := decodeResponseBody(res)
+	if err != nil {
+		res.Body.Close()
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(resBody)
+	resBody.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := &CassetteInteraction{
+		Request: CassetteRequest{
+			Method: req.Method,
+			Url:    redactedReq.URL.String(),
+			Header: redactedReq.Header,
+		},
+		Response: CassetteResponse{
+			StatusCode: res.StatusCode,
+			Status:     res.Status,
+			Header:     v.redaction.Header(res.Header),
+		},
+	}
+	interaction.Request.Body, interaction.Request.BodyEncoding = cassetteEncodeBody(redactedBody)
+	interaction.Response.Body, interaction.Response.BodyEncoding = cassetteEncodeBody(v.redactBody(res.Header, data))
+	// The stored body is already decoded.
+	interaction.Response.Header.Del("Content-Encoding")
+	interaction.Response.Header.Del("Content-Length")
+	// The live response keeps what the cassette redacts.
+	live := interaction.Response
+	live.Body, live.BodyEncoding = cassetteEncodeBody(data)
+	live.Header = res.Header.Clone()
+	live.Header.Del("Content-Encoding")
+	live.Header.Del("Content-Length")
+
+	v.mu.Lock()
+	v.cassette.Interactions = append(v.cassette.Interactions, interaction)
+	v.played[interaction] = true
+	err = v.save()
+	v.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	return live.response(req), nil
+}
+
+// redactRequest returns a copy of the request with the url, headers and body
+// as stored in the cassette.
+func (v *Vcr) redactRequest(req *http.Request, body []byte) (*http.Request, []byte) {
+	redacted := req.Clone(req.Context())
+	if u, err := url.Parse(v.redaction.Url(req.URL)); err == nil {
+		redacted.URL = u
+	}
+	redacted.Header = v.redaction.Header(req.Header)
+	return redacted, v.redactBody(req.Header, body)
+}
+
+func (v *Vcr) redactBody(header http.Header, body []byte) []byte {
+	redacted, ok := v.redaction.redact(header.Get("Content-Type"), body)
+	if !ok {
+		return []byte(unredactableBody)
+	}
+	return redacted
+}
+
+// match returns the first unplayed matching interaction, outside of strict
+// mode interactions may be replayed again once all matches are played.
+func (v *Vcr) match(req *http.Request, body []byte) *CassetteInteraction {
+	var replayed *CassetteInteraction
+	for _, interaction := range v.cassette.Interactions {
+		if !v.matches(req, body, &interaction.Request) {
+			continue
+		}
+		if !v.played[interaction] {
+			v.played[interaction] = true
+			return interaction
+		}
+		if replayed == nil {
+			replayed = interaction
+		}
+	}
+	if v.strict {
+		return nil
+	}
+	return replayed
+}
+
+func (v *Vcr) matches(req *http.Request, body []byte, recorded *CassetteRequest) bool {
+	for _, matcher := range v.matchers {
+		if !matcher(req, body, recorded) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *CassetteResponse) response(req *http.Request) *http.Response {
+	body := r.BodyBytes()
+	status := r.Status
+	if len(status) == 0 {
+		status = strconv.Itoa(r.StatusCode) + " " + http.StatusText(r.StatusCode)
+	}
+	header := r.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        status,
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+func cassetteEncodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func cassetteDecodeBody(body, encoding string) []byte {
+	if encoding == "base64" {
+		data, _ := base64.StdEncoding.DecodeString(body)
+		return data
+	}
+	return []byte(body)
+}
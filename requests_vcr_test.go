@@ -0,0 +1,188 @@
+package gorequests
+
+import (
+	"errors"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+func (suite *RequestsSuite) TestVcrRecordAndReplay() {
+	for _, name := range []string{"books.yaml", "books.json"} {
+		suite.Run(name, func() {
+			// Test data
+			path := filepath.Join(suite.T().TempDir(), "cassettes", name)
+			recorded := make(map[string]string)
+			replayed := make(map[string]string)
+
+			// Mocking http calls
+			httpmock.RegisterResponder(http.MethodGet, "http://localhost/books/1", httpmock.NewStringResponder(http.StatusOK, `{"id":"1"}`))
+
+			// Run test target
+			errRecord := Get("http://localhost/books/1").Use(NewVcr(path)).Header("Authorization", "Bearer secret").ResponseJson(&recorded).Exec()
+			httpmock.Reset()
+			vcr := NewVcr(path)
+			errReplay := Get("http://localhost/books/1").Use(vcr).ResponseJson(&replayed).Exec()
+
+			// Prepare assert stats
+			data, _ := ioutil.ReadFile(path)
+			recording, _ := vcr.Recording()
+
+			// Assertions
+			assert.NoError(suite.T(), errRecord, "should be recorded without error")
+			assert.NoError(suite.T(), errReplay, "should be replayed without error")
+			assert.Equal(suite.T(), map[string]string{"id": "1"}, recorded)
+			assert.Equal(suite.T(), recorded, replayed)
+			assert.False(suite.T(), recording, "should replay existing cassette")
+			assert.Equal(suite.T(), 0, httpmock.GetTotalCallCount(), "should not send requests while replaying")
+			assert.Contains(suite.T(), string(data), "REDACTED", "should redact credentials")
+			assert.NotContains(suite.T(), string(data), "secret")
+		})
+	}
+}
+
+func (suite *RequestsSuite) TestVcrRedactsResponseHeaders() {
+	// Test data
+	path := filepath.Join(suite.T().TempDir(), "login.yaml")
+	vcr := NewVcr(path)
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodPost, "http://localhost/login", func(request *http.Request) (*http.Response, error) {
+		res := httpmock.NewStringResponse(http.StatusOK, "")
+		res.Header.Add("Set-Cookie", "session=topsecret; Path=/")
+		return res, nil
+	})
+
+	// Run test target
+	meta := &ResponseMeta{}
+	err := Post("http://localhost/login").Use(vcr).ResponseMeta(meta).Exec()
+
+	// Prepare assert stats
+	data, _ := ioutil.ReadFile(path)
+	cassette, _ := vcr.Cassette()
+
+	// Assertions
+	assert.NoError(suite.T(), err, "should be recorded without error")
+	assert.Equal(suite.T(), "session=topsecret; Path=/", meta.Header.Get("Set-Cookie"), "should keep live response cookie")
+	assert.NotContains(suite.T(), string(data), "topsecret", "should not store session cookie")
+	if assert.Len(suite.T(), cassette.Interactions, 1) {
+		assert.Equal(suite.T(), "REDACTED", cassette.Interactions[0].Response.Header.Get("Set-Cookie"))
+	}
+}
+
+func (suite *RequestsSuite) TestVcrRedactsUrlAndBodies() {
+	// Test data
+	path := filepath.Join(suite.T().TempDir(), "users.yaml")
+	redaction := DefaultRedaction().Query("token").JsonFields("password")
+	reqUrl := "http://localhost/users?token=secret"
+	user := map[string]string{"name": "john", "password": "hunter2"}
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodPost, "http://localhost/users", func(request *http.Request) (*http.Response, error) {
+		res := httpmock.NewStringResponse(http.StatusCreated, `{"id":1,"password":"hunter3"}`)
+		res.Header.Set("Content-Type", "application/json")
+		return res, nil
+	})
+
+	// Run test target
+	recorded := make(map[string]interface{})
+	replayed := make(map[string]interface{})
+	vcr := NewVcr(path).Redaction(redaction).Matchers(VcrMatchMethod, VcrMatchUrl, VcrMatchBody)
+	errRecord := Post(reqUrl).Use(vcr).Json(user).ResponseJson(&recorded).Exec()
+	httpmock.Reset()
+	replay := NewVcr(path).Mode(VcrReplay).Redaction(redaction).Matchers(VcrMatchMethod, VcrMatchUrl, VcrMatchBody)
+	errReplay := Post(reqUrl).Use(replay).Json(user).ResponseJson(&replayed).Exec()
+
+	// Prepare assert stats
+	data, _ := ioutil.ReadFile(path)
+
+	// Assertions
+	assert.NoError(suite.T(), errRecord, "should be recorded without error")
+	assert.Equal(suite.T(), "hunter3", recorded["password"], "should keep live response body")
+	assert.NotContains(suite.T(), string(data), "secret", "should not store query token")
+	assert.NotContains(suite.T(), string(data), "hunter2", "should not store request password")
+	assert.NotContains(suite.T(), string(data), "hunter3", "should not store response password")
+	assert.NoError(suite.T(), errReplay, "should match redacted request")
+	assert.Equal(suite.T(), "REDACTED", replayed["password"])
+}
+
+func (suite *RequestsSuite) TestVcrStrict() {
+	// Test data
+	path := filepath.Join(suite.T().TempDir(), "books.yaml")
+	cassette := `interactions:
+  - request:
+      method: POST
+      url: http://localhost/books
+      body: '{"title": "A"}'
+    response:
+      statusCode: 201
+      body: created A
+  - request:
+      method: POST
+      url: http://localhost/books
+      body: '{"title": "B"}'
+    response:
+      statusCode: 201
+      body: created B
+`
+	_ = ioutil.WriteFile(path, []byte(cassette), 0644)
+	vcr := NewVcr(path).Strict(true).Matchers(VcrMatchMethod, VcrMatchUrl, VcrMatchBody)
+
+	// Run test target
+	var bodyB, bodyA []byte
+	errB := Post("http://localhost/books").Use(vcr).Json(map[string]string{"title": "B"}).ResponseRaw(&bodyB).Exec()
+	errA := Post("http://localhost/books").Use(vcr).Json(map[string]string{"title": "A"}).ResponseRaw(&bodyA).Exec()
+	errRepeat := Post("http://localhost/books").Use(vcr).Json(map[string]string{"title": "A"}).Exec()
+	errUnknown := Get("http://localhost/books").Use(vcr).Exec()
+
+	// Prepare assert stats
+	unmatched := &VcrUnmatchedError{}
+
+	// Assertions
+	assert.NoError(suite.T(), errB, "should be replayed without error")
+	assert.NoError(suite.T(), errA, "should be replayed without error")
+	assert.Equal(suite.T(), "created B", string(bodyB))
+	assert.Equal(suite.T(), "created A", string(bodyA))
+	assert.ErrorIs(suite.T(), errRepeat, ErrVcrUnmatched, "should play interactions once")
+	assert.ErrorIs(suite.T(), errUnknown, ErrVcrUnmatched)
+	if assert.True(suite.T(), errors.As(errUnknown, &unmatched)) {
+		assert.Equal(suite.T(), http.MethodGet, unmatched.Method)
+		assert.Equal(suite.T(), "http://localhost/books", unmatched.Url)
+	}
+	assert.Equal(suite.T(), 0, httpmock.GetTotalCallCount(), "should not send requests in strict mode")
+}
+
+func (suite *RequestsSuite) TestVcrModes() {
+	// Test data
+	path := filepath.Join(suite.T().TempDir(), "books.yaml")
+
+	// Mocking http calls
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/books", httpmock.NewStringResponder(http.StatusOK, "fresh"))
+	httpmock.RegisterResponder(http.MethodGet, "http://localhost/authors", httpmock.NewStringResponder(http.StatusOK, "authors"))
+
+	// Run test target
+	errMissing := Get("http://localhost/books").Use(NewVcr(path).Mode(VcrReplay)).Exec()
+	errRecord := Get("http://localhost/books").Use(NewVcr(path).Mode(VcrRecord)).Exec()
+	errAppend := Get("http://localhost/authors").Use(NewVcr(path)).Exec()
+	var body []byte
+	errReplay := Get("http://localhost/authors").Use(NewVcr(path).Mode(VcrReplay)).ResponseRaw(&body).Exec()
+
+	// Prepare assert stats
+	cassette, _ := NewVcr(path).Cassette()
+	urls := make([]string, 0)
+	for _, interaction := range cassette.Interactions {
+		urls = append(urls, interaction.Request.Url)
+	}
+
+	// Assertions
+	assert.Error(suite.T(), errMissing, "should fail without cassette")
+	assert.False(suite.T(), errors.Is(errMissing, ErrVcrUnmatched))
+	assert.NoError(suite.T(), errRecord, "should be recorded without error")
+	assert.NoError(suite.T(), errAppend, "should pass unmatched requests through")
+	assert.NoError(suite.T(), errReplay, "should be replayed without error")
+	assert.Equal(suite.T(), "authors", string(body))
+	assert.Equal(suite.T(), "http://localhost/books,http://localhost/authors", strings.Join(urls, ","))
+}
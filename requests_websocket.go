@@ -0,0 +1,421 @@
+package gorequests
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+type WebSocketMessageType int
+
+const (
+	WebSocketText   WebSocketMessageType = 1
+	WebSocketBinary WebSocketMessageType = 2
+)
+
+const (
+	WebSocketCloseNormal          = 1000
+	WebSocketCloseGoingAway       = 1001
+	WebSocketCloseProtocolError   = 1002
+	WebSocketCloseUnsupportedData = 1003
+	WebSocketCloseNoStatus        = 1005
+	WebSocketCloseInvalidPayload  = 1007
+	WebSocketCloseMessageTooBig   = 1009
+	WebSocketCloseInternalError   = 1011
+)
+
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xa
+)
+
+const webSocketGuid = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var ErrWebSocketClosed = errors.New("websocket connection closed")
+
+// WebSocketCloseError is returned by ReadMessage after a close frame.
+type WebSocketCloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *WebSocketCloseError) Error() string {
+	return fmt.Sprintf("websocket closed with code %d %s", e.Code, e.Reason)
+}
+
+func (e *WebSocketCloseError) Is(target error) bool { return target == ErrWebSocketClosed }
+
+// WebSocket performs the RFC 6455 opening handshake through the middleware
+// and returns the upgraded connection. ws and wss urls are sent as http and
+// https, a subprotocol can be requested with the Sec-WebSocket-Protocol
+// header.
+func (r *requestsInstance) WebSocket() (*WebSocketConn, error) {
+	instance := r.Clone().(*requestsInstance)
+	switch {
+	case strings.HasPrefix(instance.url, "ws://"):
+		instance.url = "http://" + strings.TrimPrefix(instance.url, "ws://")
+	case strings.HasPrefix(instance.url, "wss://"):
+		instance.url = "https://" + strings.TrimPrefix(instance.url, "wss://")
+	}
+	if len(instance.method) == 0 {
+		instance.method = http.MethodGet
+	}
+	instance.acceptEncoding = []string{}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+	instance.Header("Connection", "Upgrade").
+		Header("Upgrade", "websocket").
+		Header("Sec-WebSocket-Version", "13").
+		Header("Sec-WebSocket-Key", key)
+
+	res, err := instance.do()
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusSwitchingProtocols {
+		res.Body.Close()
+		return nil, fmt.Errorf("websocket handshake unexpected response status %s", res.Status)
+	}
+	if !strings.EqualFold(res.Header.Get("Upgrade"), "websocket") ||
+		!strings.Contains(strings.ToLower(res.Header.Get("Connection")), "upgrade") {
+		res.Body.Close()
+		return nil, fmt.Errorf("websocket handshake invalid upgrade headers")
+	}
+	if res.Header.Get("Sec-WebSocket-Accept") != webSocketAccept(key) {
+		res.Body.Close()
+		return nil, fmt.Errorf("websocket handshake invalid Sec-WebSocket-Accept")
+	}
+	rwc, ok := res.Body.(io.ReadWriteCloser)
+	if !ok {
+		res.Body.Close()
+		return nil, fmt.Errorf("websocket handshake response body is not writable")
+	}
+	conn := newWebSocketConn(rwc, bufio.NewReader(rwc), false)
+	conn.subprotocol = res.Header.Get("Sec-WebSocket-Protocol")
+	return conn, nil
+}
+
+func webSocketAccept(key string) string {
+	sum := sha1.Sum([]byte(key + webSocketGuid))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// WebSocketConn is a message oriented websocket connection. Pings are
+// answered automatically while reading. One goroutine may read while others
+// write.
+type WebSocketConn struct {
+	rwc            io.ReadWriteCloser
+	reader         *bufio.Reader
+	server         bool
+	subprotocol    string
+	readLimit      int64
+	onPong         func(data []byte)
+	readMu         sync.Mutex
+	writeMu        sync.Mutex
+	closeSent      bool
+	closeOnce      sync.Once
+	closeErr       error
+	closeTimeout   time.Duration
+	peerClosed     chan struct{}
+	peerClosedOnce sync.Once
+}
+
+func newWebSocketConn(rwc io.ReadWriteCloser, reader *bufio.Reader, server bool) *WebSocketConn {
+	return &WebSocketConn{
+		rwc:          rwc,
+		reader:       reader,
+		server:       server,
+		readLimit:    32 << 20,
+		closeTimeout: 5 * time.Second,
+		peerClosed:   make(chan struct{}),
+	}
+}
+
+func (c *WebSocketConn) Subprotocol() string {
+	return c.subprotocol
+}
+
+// SetReadLimit limits the size of received messages, 32MB by default.
+func (c *WebSocketConn) SetReadLimit(limit int64) {
+	c.readLimit = limit
+}
+
+// OnPong is called from ReadMessage for every received pong.
+func (c *WebSocketConn) OnPong(fn func(data []byte)) {
+	c.onPong = fn
+}
+
+// ReadMessage returns the next data message. After a close frame the close
+// is confirmed and a *WebSocketCloseError is returned.
+func (c *WebSocketConn) ReadMessage() (WebSocketMessageType, []byte, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	var messageType WebSocketMessageType
+	message := make([]byte, 0)
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case wsOpPong:
+			if c.onPong != nil {
+				c.onPong(payload)
+			}
+			continue
+		case wsOpClose:
+			closeErr := &WebSocketCloseError{Code: WebSocketCloseNoStatus}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			_ = c.Close(closeErr.Code, "")
+			return 0, nil, closeErr
+		case wsOpContinuation:
+			if messageType == 0 {
+				return 0, nil, c.fail(WebSocketCloseProtocolError, "unexpected continuation frame")
+			}
+		case wsOpText, wsOpBinary:
+			if messageType != 0 {
+				return 0, nil, c.fail(WebSocketCloseProtocolError, "unfinished fragmented message")
+			}
+			messageType = WebSocketMessageType(opcode)
+		default:
+			return 0, nil, c.fail(WebSocketCloseProtocolError, fmt.Sprintf("unknown opcode %d", opcode))
+		}
+		if c.readLimit > 0 && int64(len(message)+len(payload)) > c.readLimit {
+			return 0, nil, c.fail(WebSocketCloseMessageTooBig, "message too big")
+		}
+		message = append(message, payload...)
+		if fin {
+			if messageType == WebSocketText && !utf8.Valid(message) {
+				return 0, nil, c.fail(WebSocketCloseInvalidPayload, "invalid utf-8 text")
+			}
+			return messageType, message, nil
+		}
+	}
+}
+
+// ReadJson reads the next message and decodes it as json.
+func (c *WebSocketConn) ReadJson(v interface{}) error {
+	_, data, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (c *WebSocketConn) WriteMessage(messageType WebSocketMessageType, data []byte) error {
+	if messageType != WebSocketText && messageType != WebSocketBinary {
+		return fmt.Errorf("websocket unsupported message type %d", messageType)
+	}
+	return c.writeFrame(byte(messageType), data)
+}
+
+func (c *WebSocketConn) WriteText(text string) error {
+	return c.WriteMessage(WebSocketText, []byte(text))
+}
+
+func (c *WebSocketConn) WriteJson(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(WebSocketText, data)
+}
+
+func (c *WebSocketConn) Ping(data []byte) error {
+	if len(data) > 125 {
+		return fmt.Errorf("websocket ping payload too long")
+	}
+	return c.writeFrame(wsOpPing, data)
+}
+
+// Close sends a close frame, unless one was already sent, waits up to five
+// seconds for the close frame of the peer and closes the connection.
+func (c *WebSocketConn) Close(code int, reason string) error {
+	return c.close(code, reason, true)
+}
+
+func (c *WebSocketConn) close(code int, reason string, wait bool) error {
+	c.closeOnce.Do(func() {
+		payload := make([]byte, 2, 2+len(reason))
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		payload = append(payload, reason...)
+		if code == WebSocketCloseNoStatus {
+			payload = nil
+		}
+		err := c.writeFrame(wsOpClose, payload)
+		if err == nil && wait {
+			c.awaitPeerClose()
+		}
+		if closeErr := c.rwc.Close(); err == nil || errors.Is(err, ErrWebSocketClosed) {
+			err = closeErr
+		}
+		c.closeErr = err
+	})
+	return c.closeErr
+}
+
+// awaitPeerClose waits for the close frame of the peer, it is read here
+// unless another goroutine is reading messages.
+func (c *WebSocketConn) awaitPeerClose() {
+	select {
+	case <-c.peerClosed:
+		return
+	default:
+	}
+	if c.readMu.TryLock() {
+		go func() {
+			defer c.readMu.Unlock()
+			for {
+				if _, _, _, err := c.readFrame(); err != nil {
+					return
+				}
+				select {
+				case <-c.peerClosed:
+					return
+				default:
+				}
+			}
+		}()
+	}
+	timer := time.NewTimer(c.closeTimeout)
+	defer timer.Stop()
+	select {
+	case <-c.peerClosed:
+	case <-timer.C:
+	}
+}
+
+// fail closes the connection without waiting for the peer, see RFC 6455
+// section 7.1.7.
+func (c *WebSocketConn) fail(code int, reason string) error {
+	_ = c.close(code, reason, false)
+	return fmt.Errorf("websocket protocol error: %s", reason)
+}
+
+func (c *WebSocketConn) readFrame() (bool, byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0f
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(WebSocketCloseProtocolError, "reserved bits set")
+	}
+	masked := header[1]&0x80 != 0
+	if masked != c.server {
+		return false, 0, nil, c.fail(WebSocketCloseProtocolError, "invalid frame masking")
+	}
+
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(c.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(c.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended)
+	}
+	if opcode >= wsOpClose && (length > 125 || !fin) {
+		return false, 0, nil, c.fail(WebSocketCloseProtocolError, "invalid control frame")
+	}
+	if c.readLimit > 0 && length > uint64(c.readLimit) {
+		return false, 0, nil, c.fail(WebSocketCloseMessageTooBig, "message too big")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	if opcode == wsOpClose {
+		c.peerClosedOnce.Do(func() { close(c.peerClosed) })
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeFrame writes a single final frame, clients mask every frame.
+func (c *WebSocketConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return ErrWebSocketClosed
+	}
+	if opcode == wsOpClose {
+		c.closeSent = true
+	}
+
+	frame := make([]byte, 0, 14+len(payload))
+	frame = append(frame, 0x80|opcode)
+	maskBit := byte(0)
+	if !c.server {
+		maskBit = 0x80
+	}
+	switch {
+	case len(payload) <= 125:
+		frame = append(frame, maskBit|byte(len(payload)))
+	case len(payload) <= 0xffff:
+		frame = append(frame, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[len(frame)-2:], uint16(len(payload)))
+	default:
+		frame = append(frame, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[len(frame)-8:], uint64(len(payload)))
+	}
+	if c.server {
+		frame = append(frame, payload...)
+	} else {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+		frame = append(frame, mask[:]...)
+		for i, b := range payload {
+			frame = append(frame, b^mask[i%4])
+		}
+	}
+	_, err := c.rwc.Write(frame)
+	return err
+}
@@ -0,0 +1,221 @@
+package gorequests
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+// webSocketEchoServer echoes messages back, "ping" makes it ping the client
+// and "close" makes it close the connection.
+func webSocketEchoServer(handshake func(r *http.Request) bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !handshake(r) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+			"Sec-WebSocket-Protocol: " + r.Header.Get("Sec-WebSocket-Protocol") + "\r\n" +
+			"Sec-WebSocket-Accept: " + webSocketAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		_ = rw.Flush()
+
+		ws := newWebSocketConn(conn, rw.Reader, true)
+		for {
+			messageType, data, err := ws.ReadMessage()
+			if err != nil {
+				return
+			}
+			switch string(data) {
+			case "ping":
+				_ = ws.Ping([]byte("server ping"))
+			case "close":
+				_ = ws.Close(WebSocketCloseGoingAway, "bye")
+				return
+			}
+			_ = ws.WriteMessage(messageType, data)
+		}
+	}))
+}
+
+func (suite *RequestsSuite) TestWebSocket() {
+	// Test data
+	httpmock.Deactivate()
+	var handshake *http.Request
+	srv := webSocketEchoServer(func(r *http.Request) bool {
+		handshake = r
+		return true
+	})
+	defer srv.Close()
+	pongs := make([]string, 0)
+
+	// Run test target
+	conn, err := Get("ws"+strings.TrimPrefix(srv.URL, "http")+"/chat").
+		Header("Sec-WebSocket-Protocol", "chat").
+		Header("Authorization", "Bearer token").
+		Cookies(&http.Cookie{Name: "session", Value: "abc"}).
+		WebSocket()
+	if !assert.NoError(suite.T(), err, "should be connected without error") {
+		return
+	}
+	conn.OnPong(func(data []byte) { pongs = append(pongs, string(data)) })
+	errText := conn.WriteText("hello")
+	textType, text, errReadText := conn.ReadMessage()
+	large := strings.Repeat("x", 70000)
+	errBinary := conn.WriteMessage(WebSocketBinary, []byte(large))
+	binaryType, binary, errReadBinary := conn.ReadMessage()
+	errJson := conn.WriteJson(map[string]int{"id": 1})
+	decoded := make(map[string]int)
+	errReadJson := conn.ReadJson(&decoded)
+	errPing := conn.Ping([]byte("client ping"))
+	_ = conn.WriteText("ping")
+	_, pingEcho, _ := conn.ReadMessage()
+	_ = conn.WriteText("close")
+	_, _, closeErr := conn.ReadMessage()
+	writeAfterClose := conn.WriteText("late")
+
+	// Prepare assert stats
+	closeError := &WebSocketCloseError{}
+
+	// Assertions
+	assert.Equal(suite.T(), "chat", conn.Subprotocol())
+	assert.Equal(suite.T(), "Bearer token", handshake.Header.Get("Authorization"))
+	assert.Equal(suite.T(), "session=abc", handshake.Header.Get("Cookie"))
+	assert.NoError(suite.T(), errText)
+	assert.NoError(suite.T(), errReadText)
+	assert.Equal(suite.T(), WebSocketText, textType)
+	assert.Equal(suite.T(), "hello", string(text))
+	assert.NoError(suite.T(), errBinary)
+	assert.NoError(suite.T(), errReadBinary)
+	assert.Equal(suite.T(), WebSocketBinary, binaryType)
+	assert.Equal(suite.T(), large, string(binary))
+	assert.NoError(suite.T(), errJson)
+	assert.NoError(suite.T(), errReadJson)
+	assert.Equal(suite.T(), map[string]int{"id": 1}, decoded)
+	assert.NoError(suite.T(), errPing)
+	assert.Equal(suite.T(), "ping", string(pingEcho))
+	assert.Equal(suite.T(), []string{"client ping"}, pongs)
+	assert.ErrorIs(suite.T(), closeErr, ErrWebSocketClosed)
+	if assert.True(suite.T(), errors.As(closeErr, &closeError)) {
+		assert.Equal(suite.T(), WebSocketCloseGoingAway, closeError.Code)
+		assert.Equal(suite.T(), "bye", closeError.Reason)
+	}
+	assert.ErrorIs(suite.T(), writeAfterClose, ErrWebSocketClosed)
+}
+
+func (suite *RequestsSuite) TestWebSocketMiddleware() {
+	// Test data
+	httpmock.Deactivate()
+	srv := webSocketEchoServer(func(r *http.Request) bool { return true })
+	defer srv.Close()
+	entries := make([]*LogEntry, 0)
+	logger := LoggerFunc(func(ctx context.Context, entry *LogEntry) { entries = append(entries, entry) })
+	recorder := NewHarRecorder()
+
+	// Run test target
+	conn, err := Get("ws"+strings.TrimPrefix(srv.URL, "http")).
+		Use(Logging(logger).Bodies(16), recorder).
+		WebSocket()
+	if !assert.NoError(suite.T(), err, "should be connected through middleware") {
+		return
+	}
+	errText := conn.WriteText("hello")
+	_, text, errRead := conn.ReadMessage()
+	started := time.Now()
+	errClose := conn.Close(WebSocketCloseNormal, "")
+	closed := time.Since(started)
+
+	// Assertions
+	assert.NoError(suite.T(), errText)
+	assert.NoError(suite.T(), errRead)
+	assert.Equal(suite.T(), "hello", string(text))
+	assert.NoError(suite.T(), errClose)
+	assert.Less(suite.T(), closed, time.Second, "should receive close frame of the server")
+	if assert.Len(suite.T(), entries, 1, "should log handshake") {
+		assert.Equal(suite.T(), http.StatusSwitchingProtocols, entries[0].StatusCode)
+	}
+	assert.Len(suite.T(), recorder.Har().Log.Entries, 1, "should record handshake")
+}
+
+func (suite *RequestsSuite) TestWebSocketCloseTimeout() {
+	// Test data
+	httpmock.Deactivate()
+	client, server := net.Pipe()
+	defer server.Close()
+	conn := newWebSocketConn(client, bufio.NewReader(client), false)
+	conn.closeTimeout = 50 * time.Millisecond
+	go func() { _, _ = io.Copy(ioutil.Discard, server) }()
+
+	// Run test target
+	started := time.Now()
+	err := conn.Close(WebSocketCloseNormal, "")
+	closed := time.Since(started)
+
+	// Assertions
+	assert.NoError(suite.T(), err)
+	assert.GreaterOrEqual(suite.T(), closed, 50*time.Millisecond, "should wait for close frame of the peer")
+}
+
+func (suite *RequestsSuite) TestWebSocketProtocolError() {
+	// Test data
+	httpmock.Deactivate()
+	client, server := net.Pipe()
+	defer server.Close()
+	conn := newWebSocketConn(client, bufio.NewReader(client), false)
+	go func() {
+		// a frame with a reserved bit set, then the close frame is discarded
+		_, _ = server.Write([]byte{0xc1, 0x00})
+		_, _ = io.Copy(ioutil.Discard, server)
+	}()
+
+	// Run test target
+	started := time.Now()
+	_, _, err := conn.ReadMessage()
+	failed := time.Since(started)
+
+	// Assertions
+	assert.EqualError(suite.T(), err, "websocket protocol error: reserved bits set")
+	assert.Less(suite.T(), failed, time.Second, "should not wait for the peer after a protocol error")
+}
+
+func (suite *RequestsSuite) TestWebSocketHandshakeErrors() {
+	// Test data
+	httpmock.Deactivate()
+	srv := webSocketEchoServer(func(r *http.Request) bool { return false })
+	defer srv.Close()
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "Upgrade")
+		w.Header().Set("Upgrade", "websocket")
+		w.WriteHeader(http.StatusSwitchingProtocols)
+	}))
+	defer plain.Close()
+
+	// Run test target
+	_, forbiddenErr := Get(srv.URL).WebSocket()
+	done := make(chan error)
+	go func() {
+		_, err := Get(plain.URL).WebSocket()
+		done <- err
+	}()
+	var invalidErr error
+	select {
+	case invalidErr = <-done:
+	case <-time.After(5 * time.Second):
+	}
+
+	// Assertions
+	assert.EqualError(suite.T(), forbiddenErr, "websocket handshake unexpected response status 403 Forbidden")
+	assert.Error(suite.T(), invalidErr, "should reject invalid accept key")
+}